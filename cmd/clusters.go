@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/spf13/cobra"
+	"k8s-cluster-upgrade-tool/config"
+	"k8s-cluster-upgrade-tool/internal/api/aws"
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"log"
+	"os"
+	"strings"
+)
+
+// clusterResult is the outcome of running a command against a cluster
+type clusterResult struct {
+	clusterName string
+	summary     string
+	err         error
+}
+
+// addClusterSelectionFlags adds the flags to run a command against the clusters matching a selector or the clusters of
+// a group, instead of the single cluster passed
+func addClusterSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("selector", "l", "",
+		"run against the clusters whose Labels match the selector instead of a single cluster, for example env=staging,team=payments")
+	cmd.Flags().String("group", "",
+		"run against the clusters of a group configured under the groups key instead of a single cluster")
+}
+
+// selectClusters returns the names of the clusters to run the command against, which is either the cluster name
+// passed, or the clusters matching the --selector and --group flags
+func selectClusters(cmd *cobra.Command, configuration config.Configurations, clusterName string) ([]string, error) {
+	selector, _ := cmd.Flags().GetString("selector")
+	group, _ := cmd.Flags().GetString("group")
+	if selector == "" && group == "" {
+		if !configuration.IsClusterNameValid(clusterName) {
+			return nil, errors.New("please pass a valid clusterName, or a --selector or --group")
+		}
+		return []string{clusterName}, nil
+	}
+	if clusterName != "" {
+		return nil, errors.New("please pass either a clusterName, or a --selector or --group, not both")
+	}
+
+	return configuration.SelectClusters(selector, group)
+}
+
+// newK8sClient returns the client of a kube context read from a kubeconfig, it can be replaced to run commands against
+// fake clusters
+var newK8sClient = k8s.NewClient
+
+// k8sClientFor returns the client of the kube context, which is read from the kubeconfig passed with --kubeconfig, or
+// else from the one in KUBECONFIG or ~/.kube/config
+func k8sClientFor(cmd *cobra.Command, kubeContext string) (k8s.Client, error) {
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+	return newK8sClient(kubeconfig, kubeContext)
+}
+
+// verifyKubeContext returns an error when the API server endpoint a kube context points to isn't the one of the EKS
+// cluster, it can be replaced to run commands against fake clusters
+var verifyKubeContext = func(kubeContext, endpoint, clusterName, awsAccount, awsRegion string) error {
+	awsGetterObj := &aws.ConfigGetter{ConfigClientInterface: &aws.Config{}}
+	cfg, err := awsGetterObj.GetConfig(context.TODO(), awsConfig.WithRegion(awsRegion), awsConfig.WithSharedConfigProfile(awsAccount))
+	if err != nil {
+		return fmt.Errorf("there was an error while initializing the aws config to verify the kube context %s: %s", kubeContext, err)
+	}
+	eksClusterGetterObj := &aws.EksClusterGetter{DescribeEksClusterInterface: &aws.EksClient{}}
+	err = eksClusterGetterObj.VerifyEndpoint(context.TODO(), cfg, clusterName, endpoint)
+	if err != nil {
+		return fmt.Errorf("the kube context %s could not be verified to point to the EKS cluster %s, pass "+
+			"--skip-context-verification to skip the verification: %s", kubeContext, clusterName, err)
+	}
+	return nil
+}
+
+// k8sClientForCluster returns the client of the kube context of a cluster, which is read from the KubeconfigPath of
+// the cluster, or else from the kubeconfig passed with --kubeconfig, KUBECONFIG or ~/.kube/config. Unless
+// --skip-context-verification is passed, the API server the kube context points to is verified to be the endpoint of
+// the EKS cluster, so that a kube context named after one cluster but pointing to another one isn't changed. Kube
+// contexts pointing to an API server which isn't an EKS endpoint, like the ones of kind clusters, can't be verified and
+// need --skip-context-verification
+func k8sClientForCluster(cmd *cobra.Command, configuration config.Configurations, clusterName string) (k8s.Client, error) {
+	kubeContext, kubeconfig, err := configuration.GetKubeContextForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if kubeconfig == "" {
+		kubeconfig, _ = cmd.Flags().GetString("kubeconfig")
+	}
+	awsAccount, awsRegion, err := configuration.GetAwsAccountAndRegionForCluster(clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Connecting to the kube context", kubeContext)
+	skipContextVerification, _ := cmd.Flags().GetBool("skip-context-verification")
+	if !skipContextVerification {
+		kubeconfigCluster, err := k8s.ContextCluster(kubeconfig, kubeContext)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := aws.ParseEksEndpointRegion(kubeconfigCluster.Server); err != nil {
+			return nil, fmt.Errorf("the kube context %s points to %s, which is not an EKS endpoint, so it can't be "+
+				"verified to point to the EKS cluster %s, pass --skip-context-verification to skip the verification",
+				kubeContext, kubeconfigCluster.Server, clusterName)
+		}
+		err = verifyKubeContext(kubeContext, kubeconfigCluster.Server, clusterName, awsAccount, awsRegion)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newK8sClient(kubeconfig, kubeContext)
+}
+
+// runForClusters creates a client for the kube context of every cluster and runs the command against it, the current
+// context of the kubeconfig is left as it is. A single cluster fails on the first error, while for several clusters the
+// outcome of every cluster is logged in a summary once all of them ran, and the process exits with an error when the
+// command failed for any of them
+func runForClusters(cmd *cobra.Command, configuration config.Configurations, clusterNames []string,
+	run func(clusterName string, client k8s.Client) (summary string, err error)) {
+	if len(clusterNames) > 1 {
+		log.Printf("Running against %d clusters: %s\n", len(clusterNames), strings.Join(clusterNames, ", "))
+	}
+
+	results := make([]clusterResult, 0, len(clusterNames))
+	for _, clusterName := range clusterNames {
+		summary := ""
+		client, err := k8sClientForCluster(cmd, configuration, clusterName)
+		if err == nil {
+			summary, err = run(clusterName, client)
+		}
+		if err != nil && len(clusterNames) == 1 {
+			log.Fatalln("Error:", err)
+		}
+		results = append(results, clusterResult{clusterName, summary, err})
+	}
+
+	if len(clusterNames) > 1 && logClusterResults(results) {
+		os.Exit(1)
+	}
+}
+
+// logClusterResults logs the outcome of running the command against every cluster, it returns whether the command
+// failed for any of them
+func logClusterResults(results []clusterResult) (failed bool) {
+	log.Println("Summary:")
+	for _, result := range results {
+		if result.err != nil {
+			log.Printf("%s: failed, %s\n", result.clusterName, result.err)
+			failed = true
+		} else {
+			log.Printf("%s: %s\n", result.clusterName, result.summary)
+		}
+	}
+	return failed
+}
+
+// clusterNameArg returns the cluster name passed as the first of the command arguments, which is only passed when the
+// command isn't run with --selector or --group and all of the argCount arguments are passed
+func clusterNameArg(args []string, argCount int) string {
+	if len(args) == argCount {
+		return args[0]
+	}
+	return ""
+}
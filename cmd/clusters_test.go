@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+
+	"k8s-cluster-upgrade-tool/config"
+)
+
+func TestK8sClientForCluster(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	assert.Nil(t, ioutil.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: kind-test
+  cluster:
+    server: https://127.0.0.1:6443
+- name: arn:aws:eks:eu-west-1:123456789012:cluster/prod-1
+  cluster:
+    server: https://ABCDEF.gr7.eu-west-1.eks.amazonaws.com
+contexts:
+- name: kind-test
+  context:
+    cluster: kind-test
+- name: prod-1
+  context:
+    cluster: arn:aws:eks:eu-west-1:123456789012:cluster/prod-1
+`), 0644))
+	configuration := config.Configurations{ClusterList: []config.ClusterListConfiguration{
+		{ClusterName: "kind-test", AwsRegion: "eu-west-1", AwsAccount: "account1", KubeconfigPath: kubeconfig},
+		{ClusterName: "prod-1", AwsRegion: "eu-west-1", AwsAccount: "prod", KubeconfigPath: kubeconfig},
+	}}
+	newCmd := func(skipContextVerification bool) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().String("kubeconfig", "", "")
+		cmd.Flags().Bool("skip-context-verification", skipContextVerification, "")
+		return cmd
+	}
+
+	t.Run("when the kube context doesn't point to an EKS endpoint it returns an error", func(t *testing.T) {
+		client, err := k8sClientForCluster(newCmd(false), configuration, "kind-test")
+
+		assert.EqualError(t, err, "the kube context kind-test points to https://127.0.0.1:6443, which is not an EKS endpoint, "+
+			"so it can't be verified to point to the EKS cluster kind-test, pass --skip-context-verification to skip the verification")
+		assert.Nil(t, client)
+	})
+
+	t.Run("when the kube context points to an EKS endpoint it is verified", func(t *testing.T) {
+		defer func(original func(kubeContext, endpoint, clusterName, awsAccount, awsRegion string) error) {
+			verifyKubeContext = original
+		}(verifyKubeContext)
+		var verified []string
+		verifyKubeContext = func(kubeContext, endpoint, clusterName, awsAccount, awsRegion string) error {
+			verified = append(verified, kubeContext, endpoint, clusterName, awsAccount, awsRegion)
+			return errors.New("not the endpoint of the EKS cluster")
+		}
+
+		_, err := k8sClientForCluster(newCmd(false), configuration, "prod-1")
+
+		assert.EqualError(t, err, "not the endpoint of the EKS cluster")
+		assert.Equal(t, []string{"prod-1", "https://ABCDEF.gr7.eu-west-1.eks.amazonaws.com", "prod-1", "prod", "eu-west-1"}, verified)
+	})
+
+	t.Run("when --skip-context-verification is passed the kube context isn't verified", func(t *testing.T) {
+		defer func(original func(kubeContext, endpoint, clusterName, awsAccount, awsRegion string) error) {
+			verifyKubeContext = original
+		}(verifyKubeContext)
+		verifyKubeContext = func(kubeContext, endpoint, clusterName, awsAccount, awsRegion string) error {
+			return errors.New("not the endpoint of the EKS cluster")
+		}
+
+		for _, clusterName := range []string{"prod-1", "kind-test"} {
+			client, err := k8sClientForCluster(newCmd(true), configuration, clusterName)
+
+			assert.Nil(t, err)
+			assert.NotNil(t, client)
+		}
+	})
+}
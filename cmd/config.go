@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Helps with managing the config file of k8s-cluster-upgrade-tool",
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+}
+
+// printConfigFileDiffs prints the diffs of the rewritten config files against the config files on disk, it returns the
+// paths of the config files in the order they were printed
+func printConfigFileDiffs(rewrittenFiles map[string][]byte) []string {
+	files := make([]string, 0, len(rewrittenFiles))
+	for file := range rewrittenFiles {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Fatalln("There was an error reading the config file:", err)
+		}
+		unifiedDiff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(string(data)),
+			B:        splitLines(string(rewrittenFiles[file])),
+			FromFile: file,
+			ToFile:   file,
+			Context:  3,
+		})
+		if err != nil {
+			log.Fatalln("There was an error diffing the config file:", err)
+		}
+		fmt.Print(unifiedDiff)
+	}
+	return files
+}
+
+// splitLines splits the text into lines keeping their line endings, unlike difflib.SplitLines it adds no empty line
+// after the last line ending
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// writeConfigFiles writes the rewritten config files once the changes are confirmed, or right away with yes
+func writeConfigFiles(rewrittenFiles map[string][]byte, files []string, changeCount int, yes bool) {
+	if !yes && !confirm(fmt.Sprintf("Write %d change(s) to %d file(s)?", changeCount, len(files))) {
+		log.Println("The config file was not changed")
+		return
+	}
+	for _, file := range files {
+		err := ioutil.WriteFile(file, rewrittenFiles[file], 0644)
+		if err != nil {
+			log.Fatalln("There was an error writing the config file:", err)
+		}
+		log.Printf("%s has been written\n", file)
+	}
+}
+
+// confirm asks the question on the terminal, it returns whether it was answered with yes
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"k8s-cluster-upgrade-tool/config"
+	"log"
+)
+
+var configBumpCmd = &cobra.Command{
+	Use:   "bump",
+	Short: "Sets the component versions to the ones recommended for a kubernetes version",
+	Long: `Sets the versions of the components to the ones recommended for the kubernetes minor version passed with --to,
+which are looked up in the compatibility table built into the tool. The table can be updated with --compatibility-file,
+whose versions take precedence over the built-in ones per component and kubernetes minor version.
+
+The versions are set under the components key, or in the componentmatrix row of the kubernetes minor version when the
+config file has a componentmatrix. Versions newer than the recommended ones, constraints the recommended versions
+satisfy and the ComponentVersions overrides of the clusters are kept. Every version set or kept is explained.
+
+The changes are printed as a diff of the config file before they are written, with --dry-run they are only printed.
+Unless --yes is passed, the changes are confirmed before writing them.
+Usage:
+$ k8s-cluster-upgrade-tool config bump --to 1.29 --dry-run
+$ k8s-cluster-upgrade-tool config bump --to 1.29 --compatibility-file compatibility.yaml --yes`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		to, _ := cmd.Flags().GetString("to")
+		compatibilityFiles, _ := cmd.Flags().GetStringSlice("compatibility-file")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		configuration, err := readConfig(cmd)
+		if err != nil {
+			log.Fatalln("There was an error reading config from the config file:", err)
+		}
+		logConfigFileUsed(configuration)
+		if configuration.Remote != nil {
+			log.Fatalf("The config file %s is fetched from a URL, please bump the config file published there instead\n",
+				configuration.Remote.URL)
+		}
+		if configuration.MigratedFromVersion != 0 {
+			log.Fatalf("The config file %s is of the schema version %d, please migrate it with config migrate first\n",
+				configuration.File, configuration.MigratedFromVersion)
+		}
+		if len(configuration.Overrides) != 0 {
+			log.Fatalf("The config file is written with the recommended versions, please unset the environment variables overriding it, like %s\n",
+				configuration.Overrides[0].EnvironmentVariable)
+		}
+
+		table, err := config.ReadCompatibilityTable(compatibilityFiles...)
+		if err != nil {
+			log.Fatalln("There was an error reading the compatibility table:", err)
+		}
+		data, changes, err := configuration.Bump(table, to)
+		if err != nil {
+			log.Fatalln("There was an error bumping the config file:", err)
+		}
+
+		bumpedFiles := map[string][]byte{}
+		if data != nil {
+			bumpedFiles[configuration.File] = data
+		}
+		files := printConfigFileDiffs(bumpedFiles)
+		changeCount := 0
+		for _, change := range changes {
+			if change.From == change.To {
+				log.Printf("%s: %s, %s\n", change.Key, describeVersion(change.From), change.Reason)
+				continue
+			}
+			log.Printf("%s: %s -> %s, %s\n", change.Key, describeVersion(change.From), change.To, change.Reason)
+			changeCount++
+		}
+		if changeCount == 0 {
+			log.Printf("The config file already has the versions recommended for kubernetes %s\n", to)
+			return
+		}
+
+		if !dryRun {
+			writeConfigFiles(bumpedFiles, files, changeCount, yes)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configBumpCmd)
+
+	configBumpCmd.Flags().String("to", "", "kubernetes minor version the clusters are upgraded to, for example 1.29")
+	//nolint
+	configBumpCmd.MarkFlagRequired("to")
+	configBumpCmd.Flags().StringSlice("compatibility-file", nil,
+		"compatibility table taking precedence over the built-in one, can be passed several times")
+	configBumpCmd.Flags().Bool("dry-run", false, "only print the changes instead of writing them")
+	configBumpCmd.Flags().BoolP("yes", "y", false, "write the changes without confirming them")
+}
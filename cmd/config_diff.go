@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"k8s-cluster-upgrade-tool/config"
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"log"
+	"strings"
+)
+
+var configDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Lists the component version changes between two config files and the clusters they affect",
+	Long: `Lists the components whose versions change between two config files, for example two revisions of the config
+file in a repository, along with the clusters every change affects, and the clusters added and removed. Both config
+files are read and validated the same way every command reads the config file, the overrides of the environment
+variables apply to both.
+
+With --check-clusters the components of every affected cluster are checked, the same way postUpgradeCheck does, to
+list the clusters which would go from running the desired versions to drifting from them.
+Usage:
+$ k8s-cluster-upgrade-tool config diff old.yaml new.yaml
+$ k8s-cluster-upgrade-tool config diff old.yaml new.yaml --check-clusters`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		checkClusters, _ := cmd.Flags().GetBool("check-clusters")
+
+		loader := newConfigLoader(cmd)
+		from, err := loader.Load(args[0])
+		if err != nil {
+			log.Fatalf("There was an error reading config from the config file %s: %s\n", args[0], err)
+		}
+		to, err := loader.Load(args[1])
+		if err != nil {
+			log.Fatalf("There was an error reading config from the config file %s: %s\n", args[1], err)
+		}
+
+		diff := config.Diff(from, to)
+		if len(diff.AddedClusters) != 0 {
+			fmt.Printf("Clusters added: %s\n", strings.Join(diff.AddedClusters, ", "))
+		}
+		if len(diff.RemovedClusters) != 0 {
+			fmt.Printf("Clusters removed: %s\n", strings.Join(diff.RemovedClusters, ", "))
+		}
+		if len(diff.Changes) == 0 {
+			fmt.Println("The component versions of the clusters present in both config files don't change")
+			return
+		}
+
+		fmt.Println("Component version changes:")
+		var affectedClusters []string
+		affected := map[string]bool{}
+		for _, change := range diff.Changes {
+			componentName := change.ComponentName
+			if change.KubernetesMinorVersion != "" {
+				componentName += " on kubernetes " + change.KubernetesMinorVersion
+			}
+			fmt.Printf("  %s: %s -> %s, affects %d cluster(s): %s\n", componentName, describeVersion(change.From),
+				describeVersion(change.To), len(change.ClusterNames), strings.Join(change.ClusterNames, ", "))
+			for _, clusterName := range change.ClusterNames {
+				if !affected[clusterName] {
+					affected[clusterName] = true
+					affectedClusters = append(affectedClusters, clusterName)
+				}
+			}
+		}
+
+		if checkClusters {
+			runForClusters(cmd, to, affectedClusters, func(clusterName string, client k8s.Client) (string, error) {
+				summary, err := checkDrift(client, clusterName, from, to)
+				// the summary of several clusters is logged once all of them were checked
+				if err == nil && len(affectedClusters) == 1 {
+					log.Printf("%s: %s\n", clusterName, summary)
+				}
+				return summary, err
+			})
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configDiffCmd)
+
+	configDiffCmd.Flags().Bool("check-clusters", false,
+		"check the components of the affected clusters to list the ones which would drift from the desired versions")
+}
+
+func describeVersion(version string) string {
+	if version == "" {
+		return "(not configured)"
+	}
+	return version
+}
+
+// checkDrift checks the components whose versions change on the cluster of the client, it returns a summary
+// of the components which would go from running the desired version to drifting from it, and the other way around
+func checkDrift(client k8s.Client, clusterName string, from, to config.Configurations) (string, error) {
+	kubernetesMinorVersion := ""
+	var err error
+	if from.HasComponentMatrix() {
+		kubernetesMinorVersion, err = getKubernetesMinorVersion(client, from)
+	} else if to.HasComponentMatrix() {
+		kubernetesMinorVersion, err = getKubernetesMinorVersion(client, to)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var drifting, compliant []string
+	for _, change := range config.DiffClusterVersions(from, to, clusterName, kubernetesMinorVersion) {
+		configuration := to
+		if _, err := to.GetK8sObjectForCluster(clusterName, change.ComponentName); err != nil {
+			configuration = from
+		}
+		image, err := getComponentImage(client, clusterName, change.ComponentName, configuration)
+		if err != nil {
+			return "", err
+		}
+		imageTag := image.Tag
+
+		satisfiesFrom, satisfiesTo := config.VersionSatisfies(imageTag, change.From), config.VersionSatisfies(imageTag, change.To)
+		log.Printf("%s is running on %s, which %s %s and %s %s\n", change.ComponentName, imageTag,
+			satisfiesDescription(satisfiesFrom), describeVersion(change.From), satisfiesDescription(satisfiesTo),
+			describeVersion(change.To))
+		if satisfiesFrom && !satisfiesTo {
+			drifting = append(drifting, change.ComponentName)
+		} else if !satisfiesFrom && satisfiesTo {
+			compliant = append(compliant, change.ComponentName)
+		}
+	}
+
+	summary := "no component goes from compliant to drifted"
+	if len(drifting) != 0 {
+		summary = fmt.Sprintf("%d component(s) go from compliant to drifted: %s", len(drifting), strings.Join(drifting, ", "))
+	}
+	if len(compliant) != 0 {
+		summary += fmt.Sprintf(", %d component(s) go from drifted to compliant: %s", len(compliant), strings.Join(compliant, ", "))
+	}
+	return summary, nil
+}
+
+func satisfiesDescription(satisfies bool) string {
+	if satisfies {
+		return "satisfies"
+	}
+	return "doesn't satisfy"
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/spf13/cobra"
+	toolConfig "k8s-cluster-upgrade-tool/config"
+	"k8s-cluster-upgrade-tool/internal/api/aws"
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"log"
+)
+
+var configDiscoverCmd = &cobra.Command{
+	Use:   "discover",
+	Short: "Generates a clusterlist element by discovering the components running in a cluster",
+	Long: `Generates a clusterlist element for the cluster of the passed kube context. The workloads of the known components
+(aws-node, cluster-autoscaler, coredns, kube-proxy, ebs-csi-driver, metrics-server) are looked up in the namespace
+passed, and the AWS region and account are looked up from the EKS cluster.
+
+The element is printed, or merged into the clusterlist of a config file when --merge-into is passed, replacing the
+element with the same ClusterName if there is one. The kube context passed is set as the KubeContext of the element
+when it differs from its ClusterName, and the --kubeconfig passed as its KubeconfigPath.
+
+As the AwsAccount of a cluster is used as the AWS profile to run AWS calls with, it is set to the --aws-profile passed,
+or to the id of the AWS account of the EKS cluster when none is passed.
+
+Usage:
+$ k8s-cluster-upgrade-tool config discover valid-cluster-name
+$ k8s-cluster-upgrade-tool config discover valid-cluster-name --aws-profile=valid-aws-profile --merge-into=$HOME/.k8s-cluster-upgrade-tool/config.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		kubeContext := args[0]
+		clusterName, _ := cmd.Flags().GetString("cluster-name")
+		namespace, _ := cmd.Flags().GetString("namespace")
+		mergeInto, _ := cmd.Flags().GetString("merge-into")
+		if clusterName == "" {
+			clusterName = kubeContext
+		}
+
+		cluster := toolConfig.ClusterListConfiguration{
+			ClusterName: clusterName,
+			Components:  discoverComponentObjects(cmd, kubeContext, namespace),
+		}
+		cluster.AwsAccount, cluster.AwsRegion = discoverAwsAccountAndRegion(cmd, kubeContext)
+		if kubeContext != clusterName {
+			cluster.KubeContext = kubeContext
+		}
+		cluster.KubeconfigPath, _ = cmd.Flags().GetString("kubeconfig")
+
+		if mergeInto != "" {
+			err := toolConfig.MergeClusterIntoFile(mergeInto, cluster)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			log.Printf("%s has been merged into the clusterlist of %s\n", clusterName, mergeInto)
+			return
+		}
+
+		clusterYaml, err := toolConfig.MarshalClusterListElement(cluster)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Print(string(clusterYaml))
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configDiscoverCmd)
+
+	configDiscoverCmd.Flags().String("cluster-name", "",
+		"ClusterName of the generated clusterlist element, defaults to the kube context passed")
+	configDiscoverCmd.Flags().String("namespace", "kube-system",
+		"namespace the workloads of the components are looked up in")
+	configDiscoverCmd.Flags().String("aws-profile", "",
+		"AWS profile to look up the EKS cluster with, which is also set as the AwsAccount of the cluster")
+	configDiscoverCmd.Flags().String("aws-region", "",
+		"AWS region of the EKS cluster, only needed when it can't be read from the kube context")
+	configDiscoverCmd.Flags().String("eks-cluster-name", "",
+		"name of the EKS cluster, only needed when it can't be read from the kube context and differs from it")
+	configDiscoverCmd.Flags().String("merge-into", "",
+		"path of a config file to merge the generated clusterlist element into, instead of printing it")
+}
+
+func discoverComponentObjects(cmd *cobra.Command, kubeContext, namespace string) map[string]toolConfig.K8sObject {
+	client, err := k8sClientFor(cmd, kubeContext)
+	if err != nil {
+		log.Fatalln("Error:", err)
+	}
+	workloads, err := client.ListWorkloads(context.TODO(), namespace)
+	if err != nil {
+		log.Fatalln("Error: there was an issue while retrieving the workloads from the cluster:", err)
+	}
+
+	discoveredComponents := k8s.DiscoverComponents(workloads)
+	componentObjects := map[string]toolConfig.K8sObject{}
+	for _, knownComponent := range k8s.KnownComponents {
+		discoveredComponent, found := discoveredComponents[knownComponent.Name]
+		if !found {
+			log.Printf("%s was not found in the namespace %s\n", knownComponent.Name, namespace)
+			continue
+		}
+
+		log.Printf("%s was found running as %s %s in the container %s\n", knownComponent.Name,
+			discoveredComponent.ObjectType, discoveredComponent.DeploymentName, discoveredComponent.ContainerName)
+		componentObjects[knownComponent.Name] = toolConfig.K8sObject{
+			DeploymentName: discoveredComponent.DeploymentName,
+			ObjectType:     discoveredComponent.ObjectType,
+			ContainerName:  discoveredComponent.ContainerName,
+			Namespace:      discoveredComponent.Namespace,
+		}
+	}
+	return componentObjects
+}
+
+// discoverAwsAccountAndRegion looks up the EKS cluster the kube context points to, the name and region of the EKS
+// cluster are read from the kube context, which is named after the ARN of the cluster when created with
+// aws eks update-kubeconfig, or from the flags passed
+func discoverAwsAccountAndRegion(cmd *cobra.Command, kubeContext string) (awsAccount, awsRegion string) {
+	awsProfile, _ := cmd.Flags().GetString("aws-profile")
+	awsRegionFlag, _ := cmd.Flags().GetString("aws-region")
+	eksClusterName, _ := cmd.Flags().GetString("eks-cluster-name")
+	kubeconfig, _ := cmd.Flags().GetString("kubeconfig")
+
+	kubeconfigCluster, err := k8s.ContextCluster(kubeconfig, kubeContext)
+	if err != nil {
+		log.Fatalln("Error: there was an issue while reading the kube context:", err)
+	}
+
+	eksCluster, err := aws.ParseEksClusterArn(kubeconfigCluster.Name)
+	if err != nil {
+		eksCluster = aws.EksCluster{Name: kubeContext}
+		eksCluster.Region, _ = aws.ParseEksEndpointRegion(kubeconfigCluster.Server)
+	}
+	if eksClusterName != "" {
+		eksCluster.Name = eksClusterName
+	}
+	if awsRegionFlag != "" {
+		eksCluster.Region = awsRegionFlag
+	}
+	if eksCluster.Region == "" {
+		log.Println("The AWS region of the cluster could not be found, please pass it with --aws-region")
+		return awsProfile, ""
+	}
+
+	awsGetterObj := &aws.ConfigGetter{ConfigClientInterface: &aws.Config{}}
+	cfg, err := awsGetterObj.GetConfig(context.TODO(), config.WithRegion(eksCluster.Region), config.WithSharedConfigProfile(awsProfile))
+	if err != nil {
+		log.Println("There was an error while initializing the aws config, please check your aws credentials:", err)
+	} else {
+		eksClusterGetterObj := &aws.EksClusterGetter{DescribeEksClusterInterface: &aws.EksClient{}}
+		describedEksCluster, err := eksClusterGetterObj.GetCluster(context.TODO(), cfg, eksCluster.Name)
+		if err != nil {
+			log.Printf("The EKS cluster %s could not be described: %s\n", eksCluster.Name, err)
+		} else {
+			eksCluster = describedEksCluster
+			log.Printf("Found the EKS cluster %s\n", eksCluster.Arn)
+		}
+	}
+
+	if awsProfile != "" {
+		return awsProfile, eksCluster.Region
+	}
+	return eksCluster.AccountId, eksCluster.Region
+}
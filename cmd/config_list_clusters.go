@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+	"text/tabwriter"
+)
+
+var configListClustersCmd = &cobra.Command{
+	Use:   "list-clusters",
+	Short: "Lists the clusters of the config along with the file each cluster was read from",
+	Long: `Lists the clusters of the config file and of the files of the clusters.d directory next to it, along with the
+file each cluster was read from.
+Usage:
+$ k8s-cluster-upgrade-tool config list-clusters`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		configuration, err := readConfig(cmd)
+		if err != nil {
+			log.Fatalln("There was an error reading config from the config file:", err)
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "CLUSTER\tREGION\tACCOUNT\tFILE")
+		for _, cluster := range configuration.ClusterList {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", cluster.ClusterName, cluster.AwsRegion, cluster.AwsAccount,
+				configuration.RelativePath(cluster.Source.File))
+		}
+		writer.Flush()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configListClustersCmd)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"k8s-cluster-upgrade-tool/config"
+	"log"
+)
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Rewrites the config file to the current schema version",
+	Long: `Rewrites a config file written for an older version of k8s-cluster-upgrade-tool to the current schema version,
+keeping its comments, along with the files of the clusters.d directory next to it. Config files of older schema versions
+are still read, as they are migrated while reading them. Every file before the migration is kept next to it with a .bak
+suffix, with --dry-run the migrated files are printed instead of written.
+When no path is passed, the config file passed with --config, set in K8S_CLUSTER_UPGRADE_TOOL_CONFIG or found in the
+config file search paths is migrated.
+Usage:
+$ k8s-cluster-upgrade-tool config migrate
+$ k8s-cluster-upgrade-tool config migrate path/to/config.yaml --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		configFlag, _ := cmd.Flags().GetString("config")
+		if len(args) == 1 {
+			configFlag = args[0]
+		}
+		path, err := config.Loader{}.Locate(configFlag)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		if config.IsRemote(path) {
+			log.Fatalf("The config file %s is fetched from a URL, please migrate the config file published there instead\n", path)
+		}
+
+		clustersFiles, err := config.ClustersFiles(path)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		for _, file := range append([]string{path}, clustersFiles...) {
+			migrateConfigFile(file, dryRun)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configMigrateCmd)
+
+	configMigrateCmd.Flags().Bool("dry-run", false, "print the migrated config file instead of writing it")
+}
+
+// migrateConfigFile rewrites the config file, or the clusters file, to the current schema version and keeps the file
+// before the migration next to it with a .bak suffix, with dryRun the migrated file is printed instead
+func migrateConfigFile(path string, dryRun bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatalf("There was an error reading the config file %s: %s\n", path, err)
+	}
+	// the empty files of the clusters.d directory are skipped when reading them
+	if len(bytes.TrimSpace(data)) == 0 {
+		return
+	}
+	migrated, fromVersion, err := config.Migrate(data)
+	if err != nil {
+		log.Fatalf("There was an error migrating the config file %s: %s\n", path, err)
+	}
+	if fromVersion == config.CurrentVersion {
+		log.Printf("The config file %s is already of the current schema version %d\n", path, config.CurrentVersion)
+		return
+	}
+
+	if dryRun {
+		fmt.Printf("# %s\n%s", path, migrated)
+		return
+	}
+	err = ioutil.WriteFile(path+".bak", data, 0644)
+	if err != nil {
+		log.Fatalln("There was an error writing the backup of the config file:", err)
+	}
+	err = ioutil.WriteFile(path, migrated, 0644)
+	if err != nil {
+		log.Fatalln("There was an error writing the migrated config file:", err)
+	}
+	log.Printf("The config file %s has been migrated from the schema version %d to %d, the previous version was kept in %s\n",
+		path, fromVersion, config.CurrentVersion, path+".bak")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"k8s-cluster-upgrade-tool/config"
+	"log"
+)
+
+var configRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Prints the effective clusterlist element of a cluster",
+	Long: `Prints the effective clusterlist element of a cluster, which is the element of the config file or of the
+clusters.d directory with the defaults merged into it and the overrides of the environment variables applied.
+Usage:
+$ k8s-cluster-upgrade-tool config render valid-cluster-name`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configuration, err := readConfig(cmd)
+		if err != nil {
+			log.Fatalln("There was an error reading config from the config file:", err)
+		}
+
+		cluster, err := configuration.GetCluster(args[0])
+		if err != nil {
+			log.Fatalln(err)
+		}
+		clusterYaml, err := config.MarshalClusterListElement(cluster)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Print(string(clusterYaml))
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configRenderCmd)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"k8s-cluster-upgrade-tool/config"
+	"log"
+)
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Prints the JSON Schema of the config file",
+	Long: `Prints the JSON Schema of the config file, which editors can use to autocomplete and check the config file, and
+which CI can validate the config file with without running k8s-cluster-upgrade-tool. The schema is generated from the
+config the tool reads, and describes config files of the current schema version.
+Usage:
+$ k8s-cluster-upgrade-tool config schema > config.schema.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		schema, err := config.MarshalSchema()
+		if err != nil {
+			log.Fatalln("There was an error generating the JSON Schema:", err)
+		}
+		fmt.Print(string(schema))
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"k8s-cluster-upgrade-tool/config"
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"log"
+)
+
+var configSyncFromClusterCmd = &cobra.Command{
+	Use:   "sync-from-cluster",
+	Short: "Writes the component versions running in clusters into the config file",
+	Long: `Reads the version of every component running in a cluster, the same way postUpgradeCheck does, and writes the
+versions into the config file. Versions the running ones satisfy, including constraints, are kept.
+
+By default a version is written where the cluster reads it from: the defaults, the componentmatrix row of the
+kubernetes version of the cluster or the components key, which are shared by every cluster reading them, or the
+ComponentVersions override of the cluster. A shared version is set to the version most of the clusters reading it run
+on, the clusters running on another version get a ComponentVersions override. With --per-cluster every version is
+written as a ComponentVersions override of the cluster instead.
+
+The changes are printed as a diff of the config file, and of the files of its clusters.d directory, before they are
+written, with --dry-run they are only printed. Unless --yes is passed, the changes are confirmed before writing them.
+Usage:
+$ k8s-cluster-upgrade-tool config sync-from-cluster valid-cluster-name --dry-run
+$ k8s-cluster-upgrade-tool config sync-from-cluster --selector env=staging
+$ k8s-cluster-upgrade-tool config sync-from-cluster --group canary --per-cluster --yes`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		perCluster, _ := cmd.Flags().GetBool("per-cluster")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		yes, _ := cmd.Flags().GetBool("yes")
+
+		configuration, err := readConfig(cmd)
+		if err != nil {
+			log.Fatalln("There was an error reading config from the config file:", err)
+		}
+		logConfigFileUsed(configuration)
+		if configuration.Remote != nil {
+			log.Fatalf("The config file %s is fetched from a URL, please sync the config file published there instead\n",
+				configuration.Remote.URL)
+		}
+		if configuration.MigratedFromVersion != 0 {
+			log.Fatalf("The config file %s is of the schema version %d, please migrate it with config migrate first\n",
+				configuration.File, configuration.MigratedFromVersion)
+		}
+		if len(configuration.Overrides) != 0 {
+			log.Fatalf("The config file is written with the versions running in the clusters, please unset the environment variables overriding it, like %s\n",
+				configuration.Overrides[0].EnvironmentVariable)
+		}
+
+		clusterNames, err := selectClusters(cmd, configuration, clusterNameArg(args, 1))
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		var observed []config.ObservedVersion
+		runForClusters(cmd, configuration, clusterNames, func(clusterName string, client k8s.Client) (string, error) {
+			clusterObserved, err := observeComponentVersions(client, clusterName, configuration)
+			if err != nil {
+				return "", err
+			}
+			observed = append(observed, clusterObserved...)
+			return fmt.Sprintf("%d component version(s) read", len(clusterObserved)), nil
+		})
+
+		syncedFiles, changes, err := configuration.SyncVersions(observed, perCluster)
+		if err != nil {
+			log.Fatalln("There was an error syncing the config file:", err)
+		}
+		if len(changes) == 0 {
+			log.Println("The config file already matches the component versions running in the clusters")
+			return
+		}
+
+		files := printConfigFileDiffs(syncedFiles)
+		for _, change := range changes {
+			if change.From == "" {
+				log.Printf("%s: %s\n", change.Key, change.To)
+			} else {
+				log.Printf("%s: %s -> %s\n", change.Key, change.From, change.To)
+			}
+		}
+
+		if !dryRun {
+			writeConfigFiles(syncedFiles, files, len(changes), yes)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSyncFromClusterCmd)
+	addClusterSelectionFlags(configSyncFromClusterCmd)
+
+	configSyncFromClusterCmd.Flags().Bool("per-cluster", false,
+		"write every version as a ComponentVersions override of the cluster, instead of where the cluster reads it from")
+	configSyncFromClusterCmd.Flags().Bool("dry-run", false, "only print the changes instead of writing them")
+	configSyncFromClusterCmd.Flags().BoolP("yes", "y", false, "write the changes without confirming them")
+}
+
+// observeComponentVersions returns the versions of the components running on the cluster of the client
+func observeComponentVersions(client k8s.Client, clusterName string, configuration config.Configurations) ([]config.ObservedVersion, error) {
+	kubernetesMinorVersion, err := getKubernetesMinorVersion(client, configuration)
+	if err != nil {
+		return nil, err
+	}
+	componentVersions, err := configuration.GetComponentVersionsForCluster(clusterName, kubernetesMinorVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var observed []config.ObservedVersion
+	for _, componentName := range componentVersions.Names() {
+		image, err := getComponentImage(client, clusterName, componentName, configuration)
+		if err != nil {
+			return nil, err
+		}
+		if image.Tag == "" {
+			return nil, fmt.Errorf("%s is running the image %s, which has no tag to read the version from", componentName, image)
+		}
+		imageTag := image.Tag
+		log.Printf("%s is running on %s\n", componentName, imageTag)
+		observed = append(observed, config.ObservedVersion{
+			ClusterName:            clusterName,
+			KubernetesMinorVersion: kubernetesMinorVersion,
+			ComponentName:          componentName,
+			Version:                imageTag,
+		})
+	}
+	return observed, nil
+}
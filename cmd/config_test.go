@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"when the text ends with a line ending no empty line is added", "a\nb\n", []string{"a\n", "b\n"}},
+		{"when the text doesn't end with a line ending the last line is kept", "a\nb", []string{"a\n", "b"}},
+		{"when the text is empty it returns no lines", "", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, splitLines(tt.text))
+		})
+	}
+}
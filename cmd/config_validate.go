@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/spf13/cobra"
+	"k8s-cluster-upgrade-tool/config"
+	"log"
+	"os"
+)
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validates the config file and lists every problem found in it",
+	Long: `Validates the config file and lists every problem found in it along with the key it was found at,
+exits with a non-zero status code when a problem is found, which allows running it in CI.
+When no path is passed, the config file passed with --config, set in K8S_CLUSTER_UPGRADE_TOOL_CONFIG or found in the
+config file search paths is validated.
+Usage:
+$ k8s-cluster-upgrade-tool config validate
+$ k8s-cluster-upgrade-tool config validate path/to/config.yaml`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		configFlag, _ := cmd.Flags().GetString("config")
+		if len(args) == 1 {
+			configFlag = args[0]
+		}
+		loader := newConfigLoader(cmd)
+		path, err := loader.Locate(configFlag)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		configuration, err := loader.Load(path)
+		var validationErrors config.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, validationError := range validationErrors {
+				fmt.Println(validationError)
+			}
+			fmt.Printf("%d problem(s) found in the config file %s\n", len(validationErrors), path)
+			os.Exit(1)
+		}
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if configuration.Remote != nil && configuration.Remote.FetchError != nil {
+			fmt.Printf("There was an error fetching the config file %s, the last good copy cached in %s was validated instead: %s\n",
+				path, configuration.File, configuration.Remote.FetchError)
+		}
+		fmt.Printf("The config file %s is valid\n", path)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+}
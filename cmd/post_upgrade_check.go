@@ -1,167 +1,177 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"log"
-	"os/exec"
 	"strings"
 
 	"k8s-cluster-upgrade-tool/config"
 	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"k8s-cluster-upgrade-tool/internal/semver"
 )
 
+// postUpgradeCheckConfiguration is the config read in PreRun, so that a remote config file is only fetched once
+var postUpgradeCheckConfiguration config.Configurations
+
 var postUpgradeCheckCmd = &cobra.Command{
 	Use:   "postUpgradeCheck",
 	Short: "Runs post upgrade checks on a cluster",
-	Long: `Just checks for a cluster to see whether all the components have been upgraded or not
+	Long: `Just checks for a cluster to see whether all the components have been upgraded or not,
+the clusters matching a selector or the clusters of a group can be checked at once
 Usage:
-$ k8s-cluster-upgrade-tool postUpgradeCheck valid-cluster-name`,
-	Args: cobra.ExactArgs(1),
+$ k8s-cluster-upgrade-tool postUpgradeCheck valid-cluster-name
+$ k8s-cluster-upgrade-tool postUpgradeCheck --selector env=staging
+$ k8s-cluster-upgrade-tool postUpgradeCheck --group canary`,
+	Args: cobra.MaximumNArgs(1),
 	PreRun: func(cmd *cobra.Command, args []string) {
 		// Read config from file
-		configFileName, configFileType, configFilePath := config.FileMetadata()
-		configuration, err := config.Read(configFileName, configFileType, configFilePath)
+		configuration, err := readConfig(cmd)
 		if err != nil {
-			log.Fatalln("There was an error reading config from the config file")
+			log.Fatalln("There was an error reading config from the config file:", err)
 		}
 
-		log.Println("Config file used:", viper.ConfigFileUsed())
-		log.Printf("aws-node version read from config: %s\n", viper.Get("components.aws-node"))
-		log.Printf("coredns version read from config: %s", viper.Get("components.coredns"))
-		log.Printf("kube-proxy version read from config: %s", viper.Get("components.kube-proxy"))
-		log.Printf("cluster-autoscaler version read from config: %s", viper.Get("components.cluster-autoscaler"))
-
-		if configuration.IsClusterNameValid(args[0]) {
-			log.Println("Setting kubernetes context to", args[0])
-			k8s.SetK8sContext(args[0])
-		} else {
-			log.Fatal("Please pass a valid clusterName")
+		logConfigFileUsed(configuration)
+
+		_, err = selectClusters(cmd, configuration, clusterNameArg(args, 1))
+		if err != nil {
+			log.Fatalln(err)
 		}
+		postUpgradeCheckConfiguration = configuration
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		// Read config from file
-		configFileName, configFileType, configFilePath := config.FileMetadata()
-		configuration, err := config.Read(configFileName, configFileType, configFilePath)
+		configuration := postUpgradeCheckConfiguration
+		clusterNames, err := selectClusters(cmd, configuration, clusterNameArg(args, 1))
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		log.Println("running post upgrade checks")
-		checkAwsNodeComponentVersion(args[0], configuration)
-		checkKubeProxyComponentVersion(args[0], configuration)
-		checkCoreDnsComponentVersion(args[0], configuration)
-		checkClusterAutoscalerVersion(args[0], configuration)
+		runForClusters(cmd, configuration, clusterNames, func(clusterName string, client k8s.Client) (string, error) {
+			return postUpgradeCheck(client, clusterName, configuration)
+		})
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(postUpgradeCheckCmd)
+	addClusterSelectionFlags(postUpgradeCheckCmd)
 
 	// TODO Move the flags to required ones similar to taint-and-drain-asg command
 }
 
-func checkAwsNodeComponentVersion(clusterName string, configuration config.Configurations) {
-	log.Println("Checking aws-node version")
-	// TODO: Change this to use to k8s client-go
-	k8sObject, err := configuration.GetK8sObjectForCluster(clusterName, "aws-node")
+// postUpgradeCheck checks the version of every component running on the cluster of the client, it returns a summary of
+// the components which need to be updated
+func postUpgradeCheck(client k8s.Client, clusterName string, configuration config.Configurations) (string, error) {
+	kubernetesMinorVersion, err := getKubernetesMinorVersion(client, configuration)
 	if err != nil {
-		log.Fatalln("Error: there was an error while retrieving the k8sobject name and object type from the config")
+		return "", err
 	}
-	args := strings.Fields(k8s.KubectlGetImageCommand(k8sObject.ObjectType, k8sObject.DeploymentName, k8sObject.Namespace))
-
-	output, err := exec.Command(args[0], args[1:]...).Output()
+	logComponentVersions(configuration, clusterName, kubernetesMinorVersion)
+	if configuration.HasComponentMatrix() {
+		log.Printf("Using the componentmatrix row for kubernetes version %s\n", kubernetesMinorVersion)
+	}
+	componentVersions, err := configuration.GetComponentVersionsForCluster(clusterName, kubernetesMinorVersion)
 	if err != nil {
-		log.Fatalln("Error: there was an issue while retrieving the information from the cluster for the aws-node component")
+		return "", err
 	}
 
-	imageTag, err := k8s.ParseComponentImage(string(output), "imageTag")
-	if err != nil {
-		log.Fatalln("Error: there was an error parsing the image from the parsed command output")
+	log.Println("running post upgrade checks")
+	var outdatedComponents []string
+	for _, componentName := range componentVersions.Names() {
+		upToDate, err := checkComponentVersion(client, clusterName, componentName, componentVersions[componentName], configuration)
+		if err != nil {
+			return "", err
+		}
+		if !upToDate {
+			outdatedComponents = append(outdatedComponents, componentName)
+		}
 	}
-	if imageTag == viper.Get("components.aws-node") {
-		log.Printf("AWS Node Version on %s ✓ \n", viper.Get("components.aws-node"))
-	} else {
-		log.Printf("aws-node needs to be updated, is currently on %s, desired version: %s\n", imageTag,
-			viper.Get("components.aws-node"))
+
+	if len(outdatedComponents) == 0 {
+		return "all components are on the desired version", nil
 	}
+	return fmt.Sprintf("%d component(s) not on the desired version: %s", len(outdatedComponents),
+		strings.Join(outdatedComponents, ", ")), nil
 }
 
-func checkKubeProxyComponentVersion(clusterName string, configuration config.Configurations) {
-	log.Println("Checking kube-proxy version")
-	// TODO: Change this to use to k8s client-go
-	k8sObject, err := configuration.GetK8sObjectForCluster(clusterName, "kube-proxy")
+// checkComponentVersion logs how the version of the component running on the cluster compares to the desired version,
+// it returns whether the component runs on the desired version
+func checkComponentVersion(client k8s.Client, clusterName, componentName, desiredVersion string, configuration config.Configurations) (bool, error) {
+	log.Printf("Checking %s version\n", componentName)
+	image, err := getComponentImage(client, clusterName, componentName, configuration)
 	if err != nil {
-		log.Fatalln("Error: there was an error while retrieving the k8sobject name and object type from the config")
+		return false, err
 	}
-	args := strings.Fields(k8s.KubectlGetImageCommand(k8sObject.ObjectType, k8sObject.DeploymentName, k8sObject.Namespace))
+	imagePrefix, imageTag := image.Name(), image.Tag
 
-	output, err := exec.Command(args[0], args[1:]...).Output()
+	// the image repository is only checked when one is configured for the component, or when the image is pulled from
+	// an EKS registry, which needs to be the one of the region of the cluster
+	desiredImage, imageConfigured, err := configuration.GetImageForCluster(clusterName, componentName)
 	if err != nil {
-		log.Fatalln("Error: there was an issue while retrieving the information from the cluster for the kube-proxy component")
+		return false, err
 	}
-
-	imageTag, err := k8s.ParseComponentImage(string(output), "imageTag")
-	if err != nil {
-		log.Fatalln("Error: there was an error parsing the image from the parsed command output")
+	if imageConfigured && imagePrefix != desiredImage {
+		log.Printf("%s needs to be updated, is currently running the image %s, desired image: %s:%s\n",
+			componentName, image, desiredImage, desiredVersion)
+		return false, nil
 	}
-
-	if imageTag == viper.Get("components.kube-proxy") {
-		log.Printf("kube-proxy on %s ✓ \n", viper.Get("components.kube-proxy"))
-	} else {
-		log.Printf("kube-proxy needs to be updated, is currently on %s, desired version: %s\n", imageTag,
-			viper.Get("components.kube-proxy"))
+	if !imageConfigured {
+		desiredImage, eksImage, err := configuration.GetEksImageForCluster(clusterName, imagePrefix)
+		if err != nil {
+			return false, err
+		}
+		if eksImage && imagePrefix != desiredImage {
+			log.Printf("%s needs to be updated, is currently running the image %s from the EKS registry of another region, desired image: %s:%s\n",
+				componentName, image, desiredImage, desiredVersion)
+			return false, nil
+		}
 	}
-}
-
-func checkCoreDnsComponentVersion(clusterName string, configuration config.Configurations) {
-	log.Println("Checking coredns version")
-	// TODO: Change this to use to k8s client-go
-	k8sObject, err := configuration.GetK8sObjectForCluster(clusterName, "coredns")
-	if err != nil {
-		log.Fatalln("Error: there was an error while retrieving the k8sobject name and object type from the config")
-	}
-	args := strings.Fields(k8s.KubectlGetImageCommand(k8sObject.ObjectType, k8sObject.DeploymentName, k8sObject.Namespace))
-	output, err := exec.Command(args[0], args[1:]...).Output()
-	if err != nil {
-		log.Fatalln("Error: there was an issue while retrieving the information from the cluster for the coredns component")
+	// an image pinned to a digest without a tag has no version to compare
+	if imageTag == "" {
+		log.Printf("%s needs to be updated, is currently running the image %s which has no tag, desired version: %s\n",
+			componentName, image, desiredVersion)
+		return false, nil
 	}
 
-	imageTag, err := k8s.ParseComponentImage(string(output), "imageTag")
+	result, err := semver.Check(imageTag, desiredVersion)
 	if err != nil {
-		log.Fatalln("Error: there was an error parsing the image from the parsed command output")
-	}
-
-	if imageTag == viper.Get("components.coredns") {
-		log.Printf("core-dns on %s ✓ \n", viper.Get("components.coredns"))
-	} else {
-		log.Printf("core-dns needs to be updated, is currently on %s, desired version: %s\n", imageTag,
-			viper.Get("components.coredns"))
-	}
+		return false, fmt.Errorf("the desired version of the %s component is invalid: %s", componentName, err)
+	}
+	switch {
+	case result == semver.Satisfies && semver.IsConstraint(desiredVersion):
+		log.Printf("%s on %s satisfies %s ✓ \n", componentName, imageTag, desiredVersion)
+	case result == semver.Satisfies:
+		log.Printf("%s on %s ✓ \n", componentName, desiredVersion)
+	case result == semver.OlderThanRequired:
+		log.Printf("%s needs to be updated, is currently on %s which is older than required, desired version: %s\n",
+			componentName, imageTag, desiredVersion)
+	case result == semver.NewerThanRequired:
+		log.Printf("%s is currently on %s which is newer than required, desired version: %s\n", componentName, imageTag,
+			desiredVersion)
+	default:
+		log.Printf("%s needs to be updated, is currently on %s, desired version: %s\n", componentName, imageTag,
+			desiredVersion)
+	}
+	return result == semver.Satisfies, nil
 }
 
-func checkClusterAutoscalerVersion(clusterName string, configuration config.Configurations) {
-	log.Println("Checking cluster-autoscaler version")
-	// TODO: Change this to use to k8s client-go
-	k8sObject, err := configuration.GetK8sObjectForCluster(clusterName, "cluster-autoscaler")
+// getComponentImage returns the reference of the image the component runs with on the cluster of the client
+func getComponentImage(client k8s.Client, clusterName, componentName string, configuration config.Configurations) (k8s.ImageReference, error) {
+	k8sObject, err := configuration.GetK8sObjectForCluster(clusterName, componentName)
 	if err != nil {
-		log.Fatalln("Error: there was an error while retrieving the k8sobject name and object type from the config")
+		return k8s.ImageReference{}, errors.New("there was an error while retrieving the k8sobject name and object type from the config")
 	}
-	args := strings.Fields(k8s.KubectlGetImageCommand(k8sObject.ObjectType, k8sObject.DeploymentName, k8sObject.Namespace))
-	output, err := exec.Command(args[0], args[1:]...).Output()
+	image, err := client.GetImage(context.TODO(), k8sObject.ObjectType, k8sObject.DeploymentName, k8sObject.Namespace,
+		k8sObject.ContainerName)
 	if err != nil {
-		log.Fatalln("Error: there was an issue while retrieving the information from the cluster for the cluster-autoscaler component")
+		return k8s.ImageReference{}, fmt.Errorf("there was an issue while retrieving the information from the cluster for the %s component: %s", componentName, err)
 	}
 
-	imageTag, err := k8s.ParseComponentImage(string(output), "imageTag")
+	imageReference, err := k8s.ParseImageReference(image)
 	if err != nil {
-		log.Fatalln("Error: there was an error parsing the image from the parsed command output")
-	}
-
-	if imageTag == viper.Get("components.cluster-autoscaler") {
-		log.Printf("cluster-autoscaler on %s ✓ \n", viper.Get("components.cluster-autoscaler"))
-	} else {
-		log.Printf("cluster-autoscaler needs to be updated, is currently on %s, desired version: %s\n", imageTag,
-			viper.Get("components.cluster-autoscaler"))
+		return k8s.ImageReference{}, fmt.Errorf("there was an error parsing the image of the %s component: %s", componentName, err)
 	}
+	return imageReference, nil
 }
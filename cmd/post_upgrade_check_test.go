@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s-cluster-upgrade-tool/config"
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"k8s-cluster-upgrade-tool/internal/api/k8s/k8sfake"
+)
+
+func TestPostUpgradeCheck(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, ioutil.WriteFile(configPath, []byte(`version: 3
+componentmatrix:
+  "1.27":
+    coredns: ">=1.10.1 <1.11"
+    kube-proxy: v1.27.4-eksbuild.2
+clusterlist:
+- ClusterName: cluster1
+  AwsRegion: eu-west-1
+  AwsAccount: account1
+  Components:
+    coredns:
+      ObjectType: deployment
+      DeploymentName: coredns
+      ContainerName: coredns
+      Namespace: kube-system
+    kube-proxy:
+      ObjectType: daemonset
+      DeploymentName: kube-proxy
+      ContainerName: kube-proxy
+      Namespace: kube-system
+`), 0644))
+	configuration, err := config.Loader{Getenv: func(string) string { return "" }}.Load(configPath)
+	assert.Nil(t, err)
+	newClient := func(kubeProxyImage string) k8s.Client {
+		return k8sfake.NewClient("v1.27.4-eks-2d98532",
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+				// a sidecar injected ahead of the container of the component
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.12.0"},
+					{Name: "coredns", Image: "602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/coredns:v1.10.1-eksbuild.2"},
+				}}}},
+			},
+			&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "kube-proxy", Namespace: "kube-system"},
+				Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "kube-proxy", Image: kubeProxyImage},
+				}}}},
+			},
+		)
+	}
+
+	t.Run("when every component runs on the desired version it returns so", func(t *testing.T) {
+		summary, err := postUpgradeCheck(newClient("602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/kube-proxy:v1.27.4-eksbuild.2"),
+			"cluster1", configuration)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "all components are on the desired version", summary)
+	})
+
+	t.Run("when a component runs on another version it returns the outdated components", func(t *testing.T) {
+		summary, err := postUpgradeCheck(newClient("602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/kube-proxy:v1.26.6-eksbuild.2"),
+			"cluster1", configuration)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "1 component(s) not on the desired version: kube-proxy", summary)
+	})
+
+	t.Run("when the image of a component is pulled from the EKS registry of another region it is outdated", func(t *testing.T) {
+		summary, err := postUpgradeCheck(newClient("602401143452.dkr.ecr.us-east-1.amazonaws.com/eks/kube-proxy:v1.27.4-eksbuild.2"),
+			"cluster1", configuration)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "1 component(s) not on the desired version: kube-proxy", summary)
+	})
+
+	t.Run("when the image of a component is pinned to a digest along with its tag the tag is checked", func(t *testing.T) {
+		summary, err := postUpgradeCheck(newClient("602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/kube-proxy:v1.27.4-eksbuild.2"+
+			"@sha256:4a6a6b1b6d8d1b5c6e0c0b1f2b9b2d3b1c5c7e6f9a8b7c6d5e4f3a2b1c0d9e8f"), "cluster1", configuration)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "all components are on the desired version", summary)
+	})
+
+	t.Run("when the image of a component is pinned to a digest without a tag it is outdated", func(t *testing.T) {
+		summary, err := postUpgradeCheck(newClient("602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/kube-proxy"+
+			"@sha256:4a6a6b1b6d8d1b5c6e0c0b1f2b9b2d3b1c5c7e6f9a8b7c6d5e4f3a2b1c0d9e8f"), "cluster1", configuration)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "1 component(s) not on the desired version: kube-proxy", summary)
+	})
+
+	t.Run("when the image of a component is not valid it returns an error", func(t *testing.T) {
+		_, err := postUpgradeCheck(newClient("602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/kube-proxy:"), "cluster1", configuration)
+
+		assert.EqualError(t, err, `there was an error parsing the image of the kube-proxy component: invalid image `+
+			`602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/kube-proxy:, "" is not a valid tag`)
+	})
+}
@@ -1,8 +1,16 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	awsConfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/spf13/cobra"
+	"k8s-cluster-upgrade-tool/config"
+	"k8s-cluster-upgrade-tool/internal/api/aws"
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"log"
+	"net/url"
 	"os"
 )
 
@@ -11,9 +19,126 @@ var RootCmd = &cobra.Command{
 	Short: "k8s-cluster-upgrade-tool",
 }
 
+func init() {
+	RootCmd.PersistentFlags().String("config", "",
+		"path or http(s):// or s3:// URL of the config file, takes precedence over "+config.FileEnvironmentVariable+
+			" and the config file search paths")
+	RootCmd.PersistentFlags().String("kubeconfig", "",
+		"path of the kubeconfig to read the kube contexts of the clusters from, takes precedence over KUBECONFIG and ~/.kube/config")
+	RootCmd.PersistentFlags().Bool("skip-context-verification", false,
+		"skip verifying that the kube context of a cluster points to the API server of its EKS cluster")
+	RootCmd.PersistentFlags().String("config-sha256", "",
+		"SHA-256 checksum the config file fetched from a URL needs to match, takes precedence over "+config.Sha256EnvironmentVariable)
+}
+
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
+
+// readConfig reads the config file passed with --config, set in K8S_CLUSTER_UPGRADE_TOOL_CONFIG or found in the
+// config file search paths
+func readConfig(cmd *cobra.Command) (config.Configurations, error) {
+	configFlag, _ := cmd.Flags().GetString("config")
+	return newConfigLoader(cmd).Load(configFlag)
+}
+
+// newConfigLoader returns the loader of the config file, which fetches config files from s3:// URLs with the AWS
+// credentials of the environment and checks them against the --config-sha256 passed
+func newConfigLoader(cmd *cobra.Command) config.Loader {
+	sha256Flag, _ := cmd.Flags().GetString("config-sha256")
+	return config.Loader{
+		Fetchers: map[string]config.RemoteFetcher{"s3": s3Fetcher{}},
+		Sha256:   sha256Flag,
+	}
+}
+
+// s3Fetcher fetches config files from s3://bucket/path/to/key URLs, the region of the bucket can be passed with a
+// region query parameter, for example s3://bucket/config.yaml?region=eu-west-1
+type s3Fetcher struct{}
+
+func (s s3Fetcher) Fetch(location, etag string) (config.RemoteFile, error) {
+	bucket, key, err := aws.ParseS3Url(location)
+	if err != nil {
+		return config.RemoteFile{}, err
+	}
+	region := ""
+	if parsedUrl, err := url.Parse(location); err == nil {
+		region = parsedUrl.Query().Get("region")
+	}
+
+	awsGetterObj := &aws.ConfigGetter{ConfigClientInterface: &aws.Config{}}
+	cfg, err := awsGetterObj.GetConfig(context.TODO(), awsConfig.WithRegion(region), awsConfig.WithSharedConfigProfile(""))
+	if err != nil {
+		return config.RemoteFile{}, errors.New("there was an error while initializing the aws config, please check your aws credentials")
+	}
+
+	s3ObjectGetter := &aws.S3ObjectGetter{GetS3ObjectInterface: &aws.S3Client{}}
+	object, err := s3ObjectGetter.GetObject(context.TODO(), cfg, bucket, key, etag)
+	if err != nil {
+		return config.RemoteFile{}, err
+	}
+	return config.RemoteFile{Data: object.Data, ETag: object.ETag, NotModified: object.NotModified}, nil
+}
+
+// logConfigFileUsed logs the config file read, pointing out when it is of an older schema version and was migrated
+// while reading it, and the values read from environment variables instead of the config file
+func logConfigFileUsed(configuration config.Configurations) {
+	switch {
+	case configuration.Remote == nil:
+		log.Println("Config file used:", configuration.File)
+	case configuration.Remote.FetchError != nil:
+		log.Printf("There was an error fetching the config file %s, the last good copy cached in %s was used instead: %s\n",
+			configuration.Remote.URL, configuration.File, configuration.Remote.FetchError)
+	case configuration.Remote.NotModified:
+		log.Printf("Config file used: %s, which wasn't modified since it was cached in %s\n", configuration.Remote.URL,
+			configuration.File)
+	default:
+		log.Printf("Config file used: %s, fetched into %s\n", configuration.Remote.URL, configuration.File)
+	}
+	if configuration.MigratedFromVersion != 0 {
+		log.Printf("The config file is of the schema version %d and was migrated to the version %d while reading it, "+
+			"run k8s-cluster-upgrade-tool config migrate to update it\n", configuration.MigratedFromVersion, config.CurrentVersion)
+	}
+	if len(configuration.Overrides) == 0 {
+		log.Println("Every value was read from the config file")
+		return
+	}
+	for _, override := range configuration.Overrides {
+		log.Printf("%s read from the environment variable %s instead of the config file: %s\n", override.Path,
+			override.EnvironmentVariable, override.Value)
+	}
+	log.Println("Every other value was read from the config file")
+}
+
+// logComponentVersions logs the version every component is expected to run with on the cluster, pointing out the
+// versions which are overridden for the cluster
+func logComponentVersions(configuration config.Configurations, clusterName, kubernetesMinorVersion string) {
+	componentVersions, err := configuration.GetComponentVersionsForCluster(clusterName, kubernetesMinorVersion)
+	if err != nil {
+		componentVersions = configuration.Components
+	}
+	for _, componentName := range componentVersions.Names() {
+		if globalVersion, present := configuration.Components[componentName]; present && globalVersion == componentVersions[componentName] {
+			log.Printf("%s version read from config: %s\n", componentName, componentVersions[componentName])
+		} else {
+			log.Printf("%s version read from config for cluster %s: %s\n", componentName, clusterName, componentVersions[componentName])
+		}
+	}
+}
+
+// getKubernetesMinorVersion returns the kubernetes minor version of the cluster of the client when a componentmatrix is
+// configured, as the component versions depend on it, and an empty string otherwise
+func getKubernetesMinorVersion(client k8s.Client, configuration config.Configurations) (string, error) {
+	if !configuration.HasComponentMatrix() {
+		return "", nil
+	}
+
+	kubernetesMinorVersion, err := client.ServerMinorVersion(context.TODO())
+	if err != nil {
+		return "", fmt.Errorf("there was an issue while retrieving the kubernetes version of the cluster: %s", err)
+	}
+	return kubernetesMinorVersion, nil
+}
@@ -1,115 +1,123 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"k8s-cluster-upgrade-tool/config"
 	"k8s-cluster-upgrade-tool/internal/api/k8s"
 	"log"
-	"os/exec"
-	"strings"
 )
 
+// setComponentVersionConfiguration is the config read in PreRun, so that a remote config file is only fetched once
+var setComponentVersionConfiguration config.Configurations
+
 var setComponentVersionCmd = &cobra.Command{
 	Use:   "setComponentVersion",
 	Short: "Sets the value of a component running in the cluster to the passed value",
 	Long: `Sets the value of a component running in the cluster to the passed value,
-as of now will support setting the value for aws-node, cluster-autoscaler, kube-proxy, coredns
+for any of the components configured under the components key of the config file,
+the component can be set on the clusters matching a selector or the clusters of a group at once
 Usage:
-$ k8s-cluster-upgrade-tool setComponentVersion valid-cluster-name aws-node my-version`,
-	Args: cobra.ExactArgs(3),
+$ k8s-cluster-upgrade-tool setComponentVersion valid-cluster-name aws-node my-version
+$ k8s-cluster-upgrade-tool setComponentVersion --selector env=staging aws-node my-version
+$ k8s-cluster-upgrade-tool setComponentVersion --group canary aws-node my-version`,
+	Args: cobra.RangeArgs(2, 3),
 	PreRun: func(cmd *cobra.Command, args []string) {
 		// Read config from file
-		configFileName, configFileType, configFilePath := config.FileMetadata()
-		configuration, err := config.Read(configFileName, configFileType, configFilePath)
+		configuration, err := readConfig(cmd)
 		if err != nil {
-			log.Fatalln("There was an error reading config from the config file")
+			log.Fatalln("There was an error reading config from the config file:", err)
 		}
 
-		log.Println("Config file used:", viper.ConfigFileUsed())
-		log.Printf("aws-node version read from config: %s\n", viper.Get("components.aws-node"))
-		log.Printf("coredns version read from config: %s", viper.Get("components.coredns"))
-		log.Printf("kube-proxy version read from config: %s", viper.Get("components.kube-proxy"))
-		log.Printf("cluster-autoscaler version read from config: %s", viper.Get("components.cluster-autoscaler"))
+		logConfigFileUsed(configuration)
 
-		err = configuration.ValidatePassedComponentVersions(args[1], args[2])
+		_, err = selectClusters(cmd, configuration, clusterNameArg(args, 3))
 		if err != nil {
-			log.Fatalf("%s", err)
-		}
-
-		if configuration.IsClusterNameValid(args[0]) {
-			log.Println("Setting kubernetes context to", args[0])
-			k8s.SetK8sContext(args[0])
-		} else {
-			log.Fatal("Please pass a valid clusterName")
+			log.Fatalln(err)
 		}
+		setComponentVersionConfiguration = configuration
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		// Read config from file
-		configFileName, configFileType, configFilePath := config.FileMetadata()
-		configuration, err := config.Read(configFileName, configFileType, configFilePath)
+		configuration := setComponentVersionConfiguration
+		clusterNames, err := selectClusters(cmd, configuration, clusterNameArg(args, 3))
 		if err != nil {
 			log.Fatal(err)
 		}
 
-		componentName, imageTag := args[1], args[2]
-		switch componentName {
-		case "coredns":
-			k8sObject, err := configuration.GetK8sObjectForCluster(args[0], "coredns")
+		componentName, imageTag := args[len(args)-2], args[len(args)-1]
+		runForClusters(cmd, configuration, clusterNames, func(clusterName string, client k8s.Client) (string, error) {
+			// the kubernetes version of the cluster is needed to pick the componentmatrix row, so the passed version
+			// is validated once the context is set
+			kubernetesMinorVersion, err := getKubernetesMinorVersion(client, configuration)
 			if err != nil {
-				log.Fatalln("There was an error reading config from the config file")
+				return "", err
 			}
-			setComponentVersion(imageTag, componentName, fmt.Sprintf("%s.apps/%s", k8sObject.ObjectType, k8sObject.DeploymentName), k8sObject.ObjectType, k8sObject.ContainerName, k8sObject.Namespace)
-		case "kube-proxy":
-			k8sObject, err := configuration.GetK8sObjectForCluster(args[0], "kube-proxy")
+			logComponentVersions(configuration, clusterName, kubernetesMinorVersion)
+
+			err = configuration.ValidatePassedComponentVersions(clusterName, kubernetesMinorVersion, componentName, imageTag)
 			if err != nil {
-				log.Println(err)
+				return "", err
 			}
-			setComponentVersion(imageTag, componentName, fmt.Sprintf("%s.apps/%s", k8sObject.ObjectType, k8sObject.DeploymentName), k8sObject.ObjectType, k8sObject.ContainerName, k8sObject.Namespace)
-		case "aws-node":
-			k8sObject, err := configuration.GetK8sObjectForCluster(args[0], "aws-node")
+
+			k8sObject, err := configuration.GetK8sObjectForCluster(clusterName, componentName)
 			if err != nil {
-				log.Println(err)
+				return "", err
 			}
-			setComponentVersion(imageTag, componentName, fmt.Sprintf("%s.apps/%s", k8sObject.ObjectType, k8sObject.DeploymentName), k8sObject.ObjectType, k8sObject.ContainerName, k8sObject.Namespace)
-		case "cluster-autoscaler":
-			k8sObject, err := configuration.GetK8sObjectForCluster(args[0], "cluster-autoscaler")
+			imageRepository, err := getDesiredImageRepository(client, clusterName, componentName, k8sObject, configuration)
 			if err != nil {
-				log.Println(err)
+				return "", err
 			}
-			setComponentVersion(imageTag, componentName, fmt.Sprintf("%s.apps/%s", k8sObject.ObjectType, k8sObject.DeploymentName), k8sObject.ObjectType, k8sObject.ContainerName, k8sObject.Namespace)
-		default:
-			log.Println("please check the passed components, the supported components are cluster-autoscaler, kube-proxy, coredns, aws-node")
-		}
+			err = setComponentVersion(client, imageRepository, imageTag, componentName, k8sObject)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s has been set to %s", componentName, imageTag), nil
+		})
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(setComponentVersionCmd)
+	addClusterSelectionFlags(setComponentVersionCmd)
 
 	// TODO Move the flags to required ones similar to taint-and-drain-asg command
 }
 
-func setComponentVersion(imageTag, componentName, k8sSetQueryCmdObject, componentK8sObject, containerName, namespace string) {
+// getDesiredImageRepository returns the image repository to set for the component, which is the one configured for the
+// component, or else the one currently running with the registry replaced by the EKS registry of the region of the
+// cluster when it is pulled from an EKS registry
+func getDesiredImageRepository(client k8s.Client, clusterName, componentName string, k8sObject config.K8sObject, configuration config.Configurations) (string, error) {
+	imageRepository, imageConfigured, err := configuration.GetImageForCluster(clusterName, componentName)
+	if err != nil || imageConfigured {
+		return imageRepository, err
+	}
+
 	// get current imagePrefix
-	args := strings.Fields(k8s.KubectlGetImageCommand(componentK8sObject, componentName, namespace))
-	output, err := exec.Command(args[0], args[1:]...).Output()
+	image, err := client.GetImage(context.TODO(), k8sObject.ObjectType, k8sObject.DeploymentName, k8sObject.Namespace,
+		k8sObject.ContainerName)
 	if err != nil {
-		log.Fatalln("There was an error while fetching the image of the component from the cluster: ", err)
+		return "", fmt.Errorf("there was an error while fetching the image of the component from the cluster: %s", err)
 	}
 
-	imagePrefix, err := k8s.ParseComponentImage(string(output), "imagePrefix")
+	imageReference, err := k8s.ParseImageReference(image)
 	if err != nil {
-		log.Fatalln("There was an error while parsing the image prefix step: ", err)
+		return "", fmt.Errorf("there was an error while parsing the image of the component: %s", err)
 	}
+	imageRepository, _, err = configuration.GetEksImageForCluster(clusterName, imageReference.Name())
+	return imageRepository, err
+}
+
+// setComponentVersion sets the image of the component to the image repository passed with the tag passed, the digest
+// the image may have been pinned to is dropped as it is the one of the previous tag
+func setComponentVersion(client k8s.Client, imagePrefix, imageTag, componentName string, k8sObject config.K8sObject) error {
 	containerImage := imagePrefix + ":" + imageTag
 
-	args = strings.Fields(k8s.KubectlSetImageCommand(k8sSetQueryCmdObject, containerName, containerImage, namespace))
-	cmd := exec.Command(args[0], args[1:]...)
-	err = cmd.Run()
+	err := client.SetImage(context.TODO(), k8sObject.ObjectType, k8sObject.DeploymentName, k8sObject.Namespace,
+		k8sObject.ContainerName, containerImage)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	log.Printf("%s has been set to %s in cluster \n", componentName, imageTag)
+	return nil
 }
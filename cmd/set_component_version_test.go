@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s-cluster-upgrade-tool/config"
+	"k8s-cluster-upgrade-tool/internal/api/k8s/k8sfake"
+)
+
+func TestSetComponentVersion(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, ioutil.WriteFile(configPath, []byte(`version: 3
+components:
+  aws-node: v1.13.0
+  coredns: v1.10.1-eksbuild.2
+images:
+  coredns: registry.local:5000/eks/coredns
+clusterlist:
+- ClusterName: cluster1
+  AwsRegion: eu-west-1
+  AwsAccount: account1
+  Components:
+    aws-node:
+      ObjectType: daemonset
+      DeploymentName: aws-node
+      ContainerName: aws-node
+      Namespace: kube-system
+    coredns:
+      ObjectType: deployment
+      DeploymentName: coredns
+      ContainerName: coredns
+      Namespace: kube-system
+`), 0644))
+	configuration, err := config.Loader{Getenv: func(string) string { return "" }}.Load(configPath)
+	assert.Nil(t, err)
+	newClient := func(awsNodeImage string) k8sfake.Client {
+		return k8sfake.NewClient("v1.27.4-eks-2d98532",
+			&appsv1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws-node", Namespace: "kube-system"},
+				Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "aws-node", Image: awsNodeImage},
+				}}}},
+			},
+			&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{
+					{Name: "coredns", Image: "602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/coredns:v1.9.3-eksbuild.2"},
+				}}}},
+			},
+		)
+	}
+	setVersion := func(t *testing.T, client k8sfake.Client, componentName, imageTag string) {
+		k8sObject, err := configuration.GetK8sObjectForCluster("cluster1", componentName)
+		assert.Nil(t, err)
+		imageRepository, err := getDesiredImageRepository(client, "cluster1", componentName, k8sObject, configuration)
+		assert.Nil(t, err)
+		assert.Nil(t, setComponentVersion(client, imageRepository, imageTag, componentName, k8sObject))
+	}
+
+	t.Run("when the component runs from the EKS registry of another region it is set from the one of the cluster", func(t *testing.T) {
+		client := newClient("602401143452.dkr.ecr.us-west-2.amazonaws.com/amazon-k8s-cni:v1.12.6")
+
+		setVersion(t, client, "aws-node", "v1.13.0")
+
+		image, err := client.GetImage(context.TODO(), "daemonset", "aws-node", "kube-system", "aws-node")
+		assert.Nil(t, err)
+		assert.Equal(t, "602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:v1.13.0", image)
+	})
+
+	t.Run("when the component is pinned to a digest the digest is dropped", func(t *testing.T) {
+		client := newClient("602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:v1.12.6" +
+			"@sha256:4a6a6b1b6d8d1b5c6e0c0b1f2b9b2d3b1c5c7e6f9a8b7c6d5e4f3a2b1c0d9e8f")
+
+		setVersion(t, client, "aws-node", "v1.13.0")
+
+		image, err := client.GetImage(context.TODO(), "daemonset", "aws-node", "kube-system", "aws-node")
+		assert.Nil(t, err)
+		assert.Equal(t, "602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:v1.13.0", image)
+	})
+
+	t.Run("when the component has an image configured it is set from the configured image", func(t *testing.T) {
+		client := newClient("602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:v1.12.6")
+
+		setVersion(t, client, "coredns", "v1.10.1-eksbuild.2")
+
+		deployment, err := client.Clientset.AppsV1().Deployments("kube-system").Get(context.TODO(), "coredns", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, "registry.local:5000/eks/coredns:v1.10.1-eksbuild.2", deployment.Spec.Template.Spec.Containers[0].Image)
+	})
+
+	t.Run("when the object of the component doesn't exist it returns an error", func(t *testing.T) {
+		client := k8sfake.NewClient("v1.27.4-eks-2d98532")
+		k8sObject, err := configuration.GetK8sObjectForCluster("cluster1", "aws-node")
+		assert.Nil(t, err)
+
+		_, err = getDesiredImageRepository(client, "cluster1", "aws-node", k8sObject, configuration)
+
+		assert.EqualError(t, err, `there was an error while fetching the image of the component from the cluster: `+
+			`daemonsets.apps "aws-node" not found`)
+	})
+}
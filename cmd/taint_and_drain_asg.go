@@ -4,8 +4,6 @@ import (
 	"context"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
-	toolConfig "k8s-cluster-upgrade-tool/config"
 	"k8s-cluster-upgrade-tool/internal/api/aws"
 	"k8s-cluster-upgrade-tool/internal/api/k8s"
 	"log"
@@ -40,29 +38,33 @@ $ k8s-cluster-upgrade-tool taint-and-drain-asg -c=valid-cluster-name -a=eks-hash
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 
 		// Read config from file
-		configFileName, configFileType, configFilePath := toolConfig.FileMetadata()
-		configuration, err := toolConfig.Read(configFileName, configFileType, configFilePath)
+		configuration, err := readConfig(cmd)
 		if err != nil {
-			log.Fatalln("There was an error reading config from the config file")
+			log.Fatalln("There was an error reading config from the config file:", err)
 		}
 
-		log.Println("Config file used:", viper.ConfigFileUsed())
-		log.Printf("aws-node version read from config: %s\n", viper.Get("components.aws-node"))
-		log.Printf("coredns version read from config: %s", viper.Get("components.coredns"))
-		log.Printf("kube-proxy version read from config: %s", viper.Get("components.kube-proxy"))
-		log.Printf("cluster-autoscaler version read from config: %s", viper.Get("components.cluster-autoscaler"))
+		logConfigFileUsed(configuration)
 
 		// validate the cluster name and mapping if it's present
+		var k8sClient k8s.Client
 		if configuration.IsClusterNameValid(cluster) {
-			_, _, err := configuration.GetAwsAccountAndRegionForCluster(cluster)
-			if err == nil {
-				log.Println("Setting kubernetes context to", cluster)
-				k8s.SetK8sContext(cluster)
+			k8sClient, err = k8sClientForCluster(cmd, configuration, cluster)
+			if err != nil {
+				log.Fatalln(err)
 			}
 		} else {
 			log.Fatalln("Please pass a valid clusterName or check if the AWS account has a mapping inside the tool for the account and the region")
 		}
 
+		// the component versions of a componentmatrix depend on the kubernetes version of the cluster, they are only
+		// logged as the command doesn't need them
+		kubernetesMinorVersion, err := getKubernetesMinorVersion(k8sClient, configuration)
+		if err != nil {
+			log.Println(err)
+		} else {
+			logComponentVersions(configuration, cluster, kubernetesMinorVersion)
+		}
+
 		// storing all the instances with their private DNS's for the passed ASG for the AWS profile mapped for the cluster passed
 		awsAccount, awsRegion, _ := configuration.GetAwsAccountAndRegionForCluster(cluster)
 
@@ -106,21 +108,27 @@ $ k8s-cluster-upgrade-tool taint-and-drain-asg -c=valid-cluster-name -a=eks-hash
 			log.Printf("The ASG's max size was set to the current desired size, current max size after updation: %d\n",
 				awsInstances.Count())
 
-			// iterate over the nodes now to run kubectl taint
-			err = awsInstances.TaintNodes()
-			if err != nil {
-				log.Printf("Error tainting the nodes %s", err)
-			}
-
-			// iterate over the nodes now to run kubectl drain
-			err = awsInstances.DrainNodes()
-			if err != nil {
-				log.Printf("Error draining the nodes %s", err)
-			}
+			taintAndDrainNodes(k8sClient, awsInstances)
 		}
 	},
 }
 
+// taintAndDrainNodes taints every node of the instances of the ASG, so no pod is scheduled on them while they are
+// drained, and then drains them one by one
+func taintAndDrainNodes(k8sClient k8s.Client, awsInstances aws.AwsInstances) {
+	// iterate over the nodes now to taint them
+	err := awsInstances.TaintNodes(context.TODO(), k8sClient)
+	if err != nil {
+		log.Printf("Error tainting the nodes %s", err)
+	}
+
+	// iterate over the nodes now to drain them
+	err = awsInstances.DrainNodes(context.TODO(), k8sClient)
+	if err != nil {
+		log.Printf("Error draining the nodes %s", err)
+	}
+}
+
 func init() {
 	RootCmd.AddCommand(nodeTaintAndDrainCmd)
 
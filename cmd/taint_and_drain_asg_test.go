@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s-cluster-upgrade-tool/internal/api/aws"
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"k8s-cluster-upgrade-tool/internal/api/k8s/k8sfake"
+)
+
+func TestTaintAndDrainNodes(t *testing.T) {
+	pod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Spec: corev1.PodSpec{NodeName: nodeName}}
+	}
+	client := k8sfake.NewClient("v1.27.4-eks-2d98532",
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "ip-10-0-0-1.eu-west-1.compute.internal"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "ip-10-0-0-2.eu-west-1.compute.internal"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "ip-10-0-0-3.eu-west-1.compute.internal"}},
+		pod("app-1", "ip-10-0-0-1.eu-west-1.compute.internal"),
+		pod("app-2", "ip-10-0-0-2.eu-west-1.compute.internal"),
+		pod("app-3", "ip-10-0-0-3.eu-west-1.compute.internal"),
+	)
+	awsInstances := aws.AwsInstances{
+		{InstanceId: "i-1", PrivateDNS: "ip-10-0-0-1.eu-west-1.compute.internal", AsgName: "asg-1"},
+		{InstanceId: "i-2", PrivateDNS: "ip-10-0-0-2.eu-west-1.compute.internal", AsgName: "asg-1"},
+	}
+
+	taintAndDrainNodes(client, awsInstances)
+
+	nodes, err := client.Clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	for _, node := range nodes.Items {
+		if node.Name == "ip-10-0-0-3.eu-west-1.compute.internal" {
+			assert.Empty(t, node.Spec.Taints, node.Name)
+			assert.False(t, node.Spec.Unschedulable, node.Name)
+		} else {
+			assert.Equal(t, []corev1.Taint{k8s.Taint}, node.Spec.Taints, node.Name)
+			assert.True(t, node.Spec.Unschedulable, node.Name)
+		}
+	}
+	pods, err := client.Clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+	assert.Nil(t, err)
+	assert.Len(t, pods.Items, 1)
+	assert.Equal(t, "app-3", pods.Items[0].Name)
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"k8s-cluster-upgrade-tool/internal/semver"
+)
+
+// builtInCompatibilityTable is the compatibility table built into the tool, which holds the versions of the EKS
+// add-ons and the cluster-autoscaler recommended for every kubernetes minor version
+//
+//go:embed compatibility.yaml
+var builtInCompatibilityTable []byte
+
+// CompatibilityTable holds the versions of the components recommended for every kubernetes minor version
+type CompatibilityTable struct {
+	Components map[string]ComponentCompatibility `yaml:"components"`
+}
+
+// ComponentCompatibility holds the versions of a component recommended for every kubernetes minor version, for example
+// "1.29", along with the reference the versions were taken from
+type ComponentCompatibility struct {
+	Reference string            `yaml:"reference"`
+	Versions  map[string]string `yaml:"versions"`
+}
+
+// BumpChange is the outcome of bumping the version of a component, To equals From when the version was kept and Reason
+// explains why the version was set or kept
+type BumpChange struct {
+	ComponentName string
+	Key           string
+	From          string
+	To            string
+	Reason        string
+}
+
+// ReadCompatibilityTable returns the compatibility table built into the tool with the tables of the passed files
+// merged into it, the versions of the files take precedence per component and kubernetes minor version
+func ReadCompatibilityTable(paths ...string) (CompatibilityTable, error) {
+	table, err := parseCompatibilityTable(builtInCompatibilityTable, "the built-in compatibility table")
+	if err != nil {
+		return CompatibilityTable{}, err
+	}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return CompatibilityTable{}, fmt.Errorf("error reading from the compatibility table %s", path)
+		}
+		fileTable, err := parseCompatibilityTable(data, path)
+		if err != nil {
+			return CompatibilityTable{}, err
+		}
+		table.mergeFrom(fileTable)
+	}
+	return table, nil
+}
+
+func parseCompatibilityTable(data []byte, name string) (CompatibilityTable, error) {
+	var table CompatibilityTable
+	if err := yaml.Unmarshal(data, &table); err != nil {
+		return CompatibilityTable{}, fmt.Errorf("error un marshaling %s", name)
+	}
+	for _, componentName := range table.componentNames() {
+		for kubernetesMinorVersion, version := range table.Components[componentName].Versions {
+			if !kubernetesMinorVersionRegex.MatchString(kubernetesMinorVersion) {
+				return CompatibilityTable{}, fmt.Errorf("%s: components.%s.versions: %s is not a kubernetes minor version of the format 1.27",
+					name, componentName, kubernetesMinorVersion)
+			}
+			if _, err := semver.Parse(version); err != nil {
+				return CompatibilityTable{}, fmt.Errorf("%s: components.%s.versions[%q]: %s", name, componentName, kubernetesMinorVersion, err)
+			}
+		}
+	}
+	return table, nil
+}
+
+// mergeFrom merges the versions and references of the passed table into the table
+func (t *CompatibilityTable) mergeFrom(other CompatibilityTable) {
+	if t.Components == nil {
+		t.Components = map[string]ComponentCompatibility{}
+	}
+	for componentName, otherComponent := range other.Components {
+		component := t.Components[componentName]
+		if otherComponent.Reference != "" {
+			component.Reference = otherComponent.Reference
+		}
+		versions := map[string]string{}
+		for kubernetesMinorVersion, version := range component.Versions {
+			versions[kubernetesMinorVersion] = version
+		}
+		for kubernetesMinorVersion, version := range otherComponent.Versions {
+			versions[kubernetesMinorVersion] = version
+		}
+		component.Versions = versions
+		t.Components[componentName] = component
+	}
+}
+
+func (t CompatibilityTable) componentNames() []string {
+	names := make([]string, 0, len(t.Components))
+	for name := range t.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RecommendedVersion returns the version of the component recommended for the kubernetes minor version along with the
+// reference it was taken from, and whether the table has one
+func (t CompatibilityTable) RecommendedVersion(componentName, kubernetesMinorVersion string) (version, reference string, present bool) {
+	component := t.Components[componentName]
+	version, present = component.Versions[kubernetesMinorVersion]
+	return version, component.Reference, present
+}
+
+// Bump returns the contents of the config file with the component versions set to the ones the compatibility table
+// recommends for the kubernetes minor version, along with the outcome for every component. The versions are set under
+// the components key, or in the componentmatrix row of the kubernetes minor version when the config file has a
+// componentmatrix. Versions newer than the recommended ones and constraints the recommended versions satisfy are kept,
+// as are the ComponentVersions overrides of the clusters. The contents are nil when no version changes
+func (c Configurations) Bump(table CompatibilityTable, kubernetesMinorVersion string) ([]byte, []BumpChange, error) {
+	if !kubernetesMinorVersionRegex.MatchString(kubernetesMinorVersion) {
+		return nil, nil, fmt.Errorf("%s is not a kubernetes minor version of the format 1.27", kubernetesMinorVersion)
+	}
+	document, err := readCurrentVersionYamlFile(c.File)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keys, keyPrefix := []string{"components"}, "components"
+	currentVersions := c.Components
+	if c.HasComponentMatrix() {
+		keys, keyPrefix = []string{"componentmatrix", kubernetesMinorVersion}, fmt.Sprintf("componentmatrix[%q]", kubernetesMinorVersion)
+		currentVersions = c.Components.MergedWith(c.ComponentMatrix[kubernetesMinorVersion])
+	}
+
+	var changes []BumpChange
+	changed := false
+	for _, componentName := range c.allComponentVersions().Names() {
+		current := currentVersions[componentName]
+		change := BumpChange{ComponentName: componentName, Key: keyPrefix + "." + componentName, From: current, To: current}
+		recommended, reference, present := table.RecommendedVersion(componentName, kubernetesMinorVersion)
+		result, err := semver.Check(recommended, current)
+		switch {
+		case !present:
+			change.Reason = fmt.Sprintf("kept, the compatibility table has no version for kubernetes %s", kubernetesMinorVersion)
+		case current == recommended:
+			change.Reason = fmt.Sprintf("kept, the version recommended for kubernetes %s", kubernetesMinorVersion)
+		case current != "" && err == nil && semver.IsConstraint(current) && result == semver.Satisfies:
+			change.Reason = fmt.Sprintf("kept, the version %s recommended for kubernetes %s satisfies it", recommended, kubernetesMinorVersion)
+		case current != "" && err == nil && result == semver.OlderThanRequired:
+			change.Reason = fmt.Sprintf("kept, newer than the version %s recommended for kubernetes %s", recommended, kubernetesMinorVersion)
+		default:
+			change.To = recommended
+			change.Reason = fmt.Sprintf("the version recommended for kubernetes %s", kubernetesMinorVersion)
+			if reference != "" {
+				change.Reason += ", see " + reference
+			}
+			setNestedMappingValue(document.Content[0], append(append([]string{}, keys...), componentName), recommended)
+			changed = true
+		}
+		if overridingClusters := c.clustersOverriding(componentName); len(overridingClusters) != 0 {
+			change.Reason += fmt.Sprintf(", the ComponentVersions of %d cluster(s) override it and are kept: %s",
+				len(overridingClusters), strings.Join(overridingClusters, ", "))
+		}
+		changes = append(changes, change)
+	}
+
+	if !changed {
+		return nil, changes, nil
+	}
+	data, err := marshalYaml(document)
+	if err != nil {
+		return nil, nil, errors.New("error marshaling the bumped config file")
+	}
+	return data, changes, nil
+}
+
+// clustersOverriding returns the names of the clusters which override the version of the component
+func (c Configurations) clustersOverriding(componentName string) []string {
+	var clusterNames []string
+	for _, cluster := range c.ClusterList {
+		if _, present := cluster.ComponentVersions[componentName]; present {
+			clusterNames = append(clusterNames, cluster.ClusterName)
+		}
+	}
+	return clusterNames
+}
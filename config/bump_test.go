@@ -0,0 +1,163 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"k8s-cluster-upgrade-tool/internal/semver"
+)
+
+func TestReadCompatibilityTable(t *testing.T) {
+	t.Run("when no file is passed the built-in table is returned", func(t *testing.T) {
+		table, err := ReadCompatibilityTable()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"aws-node", "cluster-autoscaler", "coredns", "kube-proxy"}, table.componentNames())
+		for _, componentName := range table.componentNames() {
+			assert.NotEmpty(t, table.Components[componentName].Reference)
+			for kubernetesMinorVersion, version := range table.Components[componentName].Versions {
+				assert.Regexp(t, kubernetesMinorVersionRegex, kubernetesMinorVersion)
+				_, err := semver.Parse(version)
+				assert.Nil(t, err)
+			}
+		}
+	})
+
+	t.Run("when files are passed their versions take precedence", func(t *testing.T) {
+		first := writeTempFile(t, []byte("components:\n  coredns:\n    versions:\n      \"1.29\": v1.11.1-eksbuild.4\n      \"1.31\": v1.11.3-eksbuild.1\n"))
+		second := writeTempFile(t, []byte("components:\n  coredns:\n    versions:\n      \"1.31\": v1.11.3-eksbuild.2\n  metrics-server:\n    reference: https://github.com/kubernetes-sigs/metrics-server\n    versions:\n      \"1.29\": v0.7.1\n"))
+
+		table, err := ReadCompatibilityTable(first, second)
+
+		assert.Nil(t, err)
+		version, reference, present := table.RecommendedVersion("coredns", "1.29")
+		assert.Equal(t, "v1.11.1-eksbuild.4", version)
+		assert.Equal(t, "https://docs.aws.amazon.com/eks/latest/userguide/managing-coredns.html", reference)
+		assert.True(t, present)
+		version, _, _ = table.RecommendedVersion("coredns", "1.31")
+		assert.Equal(t, "v1.11.3-eksbuild.2", version)
+		version, _, _ = table.RecommendedVersion("coredns", "1.28")
+		assert.Equal(t, "v1.10.1-eksbuild.11", version)
+		version, reference, _ = table.RecommendedVersion("metrics-server", "1.29")
+		assert.Equal(t, "v0.7.1", version)
+		assert.Equal(t, "https://github.com/kubernetes-sigs/metrics-server", reference)
+		_, _, present = table.RecommendedVersion("metrics-server", "1.28")
+		assert.False(t, present)
+	})
+
+	t.Run("when a file is invalid an error is returned", func(t *testing.T) {
+		tests := []struct {
+			data string
+			err  string
+		}{
+			{"components: [", "error un marshaling"},
+			{"components:\n  coredns:\n    versions:\n      \"129\": v1.11.1\n", "components.coredns.versions: 129 is not a kubernetes minor version of the format 1.27"},
+			{"components:\n  coredns:\n    versions:\n      \"1.29\": latest\n", "components.coredns.versions[\"1.29\"]"},
+		}
+		for _, tt := range tests {
+			_, err := ReadCompatibilityTable(writeTempFile(t, []byte(tt.data)))
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		}
+	})
+
+	t.Run("when a file doesn't exist an error is returned", func(t *testing.T) {
+		_, err := ReadCompatibilityTable(filepath.Join(t.TempDir(), "compatibility.yaml"))
+
+		assert.Error(t, err)
+	})
+}
+
+func TestConfigurations_Bump(t *testing.T) {
+	clusterYaml := "clusterlist:\n- ClusterName: cluster1\n  AwsRegion: eu-west-1\n  AwsAccount: account1\n  Components:\n"
+	for _, componentName := range []string{"aws-node", "coredns", "kube-proxy"} {
+		clusterYaml += "    " + componentName + ":\n      ObjectType: deployment\n      DeploymentName: " + componentName +
+			"\n      ContainerName: " + componentName + "\n      Namespace: kube-system\n"
+	}
+	load := func(t *testing.T, configYaml string) Configurations {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		assert.Nil(t, ioutil.WriteFile(path, []byte(configYaml), 0644))
+		configuration, err := Loader{}.Load(path)
+		assert.Nil(t, err)
+		return configuration
+	}
+	table := CompatibilityTable{Components: map[string]ComponentCompatibility{
+		"coredns": {
+			Reference: "https://docs.aws.amazon.com/eks/latest/userguide/managing-coredns.html",
+			Versions:  map[string]string{"1.28": "v1.10.1-eksbuild.11", "1.29": "v1.11.1-eksbuild.9"},
+		},
+		"kube-proxy": {Versions: map[string]string{"1.29": "v1.29.3-eksbuild.2"}},
+	}}
+
+	t.Run("when the versions are older than the recommended ones they are set and comments are kept", func(t *testing.T) {
+		configuration := load(t, "# versions of every cluster\ncomponents:\n  coredns: \"v1.10.1-eksbuild.11\" # coredns\n  kube-proxy: v1.28.8-eksbuild.5\n"+clusterYaml)
+
+		data, changes, err := configuration.Bump(table, "1.29")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []BumpChange{
+			{ComponentName: "coredns", Key: "components.coredns", From: "v1.10.1-eksbuild.11", To: "v1.11.1-eksbuild.9",
+				Reason: "the version recommended for kubernetes 1.29, see https://docs.aws.amazon.com/eks/latest/userguide/managing-coredns.html"},
+			{ComponentName: "kube-proxy", Key: "components.kube-proxy", From: "v1.28.8-eksbuild.5", To: "v1.29.3-eksbuild.2",
+				Reason: "the version recommended for kubernetes 1.29"},
+		}, changes)
+		assert.Contains(t, string(data), "# versions of every cluster\ncomponents:\n  coredns: \"v1.11.1-eksbuild.9\" # coredns\n  kube-proxy: v1.29.3-eksbuild.2\n")
+	})
+
+	t.Run("when the config file has a componentmatrix the row of the kubernetes version is set", func(t *testing.T) {
+		configuration := load(t, "components:\n  kube-proxy: v1.28.8-eksbuild.5\ncomponentmatrix:\n  \"1.28\":\n    coredns: v1.10.1-eksbuild.11\n"+clusterYaml)
+
+		data, changes, err := configuration.Bump(table, "1.29")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{`componentmatrix["1.29"].coredns`, `componentmatrix["1.29"].kube-proxy`},
+			[]string{changes[0].Key, changes[1].Key})
+		assert.Equal(t, "", changes[0].From)
+		assert.Equal(t, "v1.28.8-eksbuild.5", changes[1].From)
+		bumped, err := Read(FileMetadataForPath(writeTempFile(t, data)))
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.10.1-eksbuild.11"}, bumped.ComponentMatrix["1.28"])
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.11.1-eksbuild.9", "kube-proxy": "v1.29.3-eksbuild.2"},
+			bumped.ComponentMatrix["1.29"])
+		assert.Equal(t, ComponentVersionConfigurations{"kube-proxy": "v1.28.8-eksbuild.5"}, bumped.Components)
+	})
+
+	t.Run("when the versions are newer, satisfied constraints or unknown to the table they are kept", func(t *testing.T) {
+		configuration := load(t, "components:\n  aws-node: v1.18.1-eksbuild.3\n  coredns: \">=1.11 <1.12\"\n  kube-proxy: v1.29.4-eksbuild.1\n"+clusterYaml)
+
+		data, changes, err := configuration.Bump(table, "1.29")
+
+		assert.Nil(t, err)
+		assert.Nil(t, data)
+		assert.Equal(t, []BumpChange{
+			{ComponentName: "aws-node", Key: "components.aws-node", From: "v1.18.1-eksbuild.3", To: "v1.18.1-eksbuild.3",
+				Reason: "kept, the compatibility table has no version for kubernetes 1.29"},
+			{ComponentName: "coredns", Key: "components.coredns", From: ">=1.11 <1.12", To: ">=1.11 <1.12",
+				Reason: "kept, the version v1.11.1-eksbuild.9 recommended for kubernetes 1.29 satisfies it"},
+			{ComponentName: "kube-proxy", Key: "components.kube-proxy", From: "v1.29.4-eksbuild.1", To: "v1.29.4-eksbuild.1",
+				Reason: "kept, newer than the version v1.29.3-eksbuild.2 recommended for kubernetes 1.29"},
+		}, changes)
+	})
+
+	t.Run("when clusters override a version they are listed", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: v1.10.1-eksbuild.11\n"+clusterYaml+"  ComponentVersions:\n    coredns: v1.9.3\n")
+
+		_, changes, err := configuration.Bump(table, "1.29")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "the version recommended for kubernetes 1.29, see https://docs.aws.amazon.com/eks/latest/userguide/managing-coredns.html"+
+			", the ComponentVersions of 1 cluster(s) override it and are kept: cluster1", changes[0].Reason)
+	})
+
+	t.Run("when the kubernetes version is invalid an error is returned", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: v1.10.1-eksbuild.11\n"+clusterYaml)
+
+		_, _, err := configuration.Bump(table, "129")
+
+		assert.EqualError(t, err, "129 is not a kubernetes minor version of the format 1.27")
+	})
+}
@@ -1,76 +1,148 @@
 package config
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"io/ioutil"
+	"k8s-cluster-upgrade-tool/internal/semver"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 )
 
 const (
 	FileName = "config"
 	FileType = "yaml"
 	FilePath = "$HOME/.k8s-cluster-upgrade-tool"
+	// FileEnvironmentVariable holds the path of the config file to read, it takes precedence over the search paths
+	FileEnvironmentVariable = "K8S_CLUSTER_UPGRADE_TOOL_CONFIG"
+	// ClustersDirName is the directory next to the config file the clusterlist can be split into files in
+	ClustersDirName = "clusters.d"
 )
 
+var kubernetesMinorVersionRegex = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// ObjectTypes are the kinds of k8s objects a component can be running as
+var ObjectTypes = []string{"daemonset", "deployment", "statefulset"}
+
 type Configurations struct {
-	Components  ComponentVersionConfigurations `mapstructure:"components"`
-	ClusterList []ClusterListConfiguration     `mapstructure:"clusterlist"`
+	Components ComponentVersionConfigurations `mapstructure:"components"`
+	// ComponentMatrix maps a kubernetes minor version, for example "1.27", to the component versions for clusters
+	// running on it. It is read separately from the rest of the config, as viper treats the dots of the minor versions
+	// as key delimiters
+	ComponentMatrix map[string]ComponentVersionConfigurations `mapstructure:"-"`
+	// Defaults are deep-merged into every element of the clusterlist, which only needs to set what differs from them
+	Defaults    ClusterListConfiguration   `mapstructure:"defaults"`
+	ClusterList []ClusterListConfiguration `mapstructure:"clusterlist"`
+	// Images maps the name of a component to the image repository, without tag, the component is expected to run from.
+	// The repository may contain the {region} and {account} placeholders, which are replaced with the AwsRegion and
+	// AwsAccount of a cluster, and the {eks-registry} placeholder, which is replaced with the EKS registry of the region
+	Images map[string]string `mapstructure:"images"`
+	// EksRegistries maps AWS regions to the ECR registry the images of the EKS add-ons are pulled from in the region,
+	// they take precedence over the registries built into the tool
+	EksRegistries map[string]string `mapstructure:"eksregistries"`
+	// Groups maps the name of a group to the clusters it consists of
+	Groups map[string]GroupConfiguration `mapstructure:"groups"`
+	// MigratedFromVersion is the schema version the config file was migrated from when it was read, it is 0 when the
+	// config file is of the CurrentVersion
+	MigratedFromVersion int `mapstructure:"-"`
+	// File is the absolute path of the config file read
+	File string `mapstructure:"-"`
+	// Overrides are the values read from environment variables instead of the config file, see EnvironmentVariablePrefix
+	Overrides []Override `mapstructure:"-"`
+	// Remote is the remote location the config file was fetched from, it is nil for local config files
+	Remote *RemoteSource `mapstructure:"-"`
 }
 
 // reference: https://stackoverflow.com/questions/63889004/how-to-access-specific-items-in-an-array-from-viper
 type ClusterListConfiguration struct {
-	ClusterName             string    `mapstructure:"ClusterName"`
-	AwsRegion               string    `mapstructure:"AwsRegion"`
-	AwsAccount              string    `mapstructure:"AwsAccount"`
-	AwsNodeObject           K8sObject `mapstructure:"AwsNodeObject"`
-	ClusterAutoscalerObject K8sObject `mapstructure:"ClusterAutoscalerObject"`
-	CoreDnsObject           K8sObject `mapstructure:"CoreDnsObject"`
-	KubeProxyObject         K8sObject `mapstructure:"KubeProxyObject"`
+	ClusterName string `mapstructure:"ClusterName" yaml:"ClusterName"`
+	AwsRegion   string `mapstructure:"AwsRegion" yaml:"AwsRegion"`
+	AwsAccount  string `mapstructure:"AwsAccount" yaml:"AwsAccount"`
+	// KubeContext is the name of the kube context of the cluster, the ClusterName is used when it is empty
+	KubeContext string `mapstructure:"KubeContext" yaml:"KubeContext,omitempty"`
+	// KubeconfigPath is the path of the kubeconfig the kube context is read from, which takes precedence over
+	// --kubeconfig, KUBECONFIG and ~/.kube/config
+	KubeconfigPath string `mapstructure:"KubeconfigPath" yaml:"KubeconfigPath,omitempty"`
+	// Components maps the name of a component, as used under the top level components key, to the k8s object the
+	// component is running as in the cluster
+	Components map[string]K8sObject `mapstructure:"Components" yaml:"Components,omitempty"`
+	// ComponentVersions overrides the versions set under the top level components key for the cluster
+	ComponentVersions ComponentVersionConfigurations `mapstructure:"ComponentVersions" yaml:"ComponentVersions,omitempty"`
+	// Labels are used to select the cluster with a selector, for example env=staging
+	Labels map[string]string `mapstructure:"Labels" yaml:"Labels,omitempty"`
+	// Source is where the cluster was read from
+	Source ClusterSource `mapstructure:"-" yaml:"-"`
 }
 
-type K8sObject struct {
-	DeploymentName string `mapstructure:"DeploymentName"`
-	ObjectType     string `mapstructure:"ObjectType"`
-	ContainerName  string `mapstructure:"ContainerName"`
-	Namespace      string `mapstructure:"Namespace"`
+// ClusterSource is the file a clusterlist element was read from, which is either the config file or one of the files
+// of its clusters.d directory, and the index of the element in the clusterlist of that file
+type ClusterSource struct {
+	File  string
+	Index int
 }
 
-type ComponentVersionConfigurations struct {
-	AwsNode           string `mapstructure:"aws-node"`
-	ClusterAutoscaler string `mapstructure:"cluster-autoscaler"`
-	CoreDns           string `mapstructure:"coredns"`
-	KubeProxy         string `mapstructure:"kube-proxy"`
+// GroupConfiguration is a named set of clusters, which consists of the clusters matching the Selector and the clusters
+// listed under Clusters
+type GroupConfiguration struct {
+	Selector string   `mapstructure:"Selector" yaml:"Selector,omitempty"`
+	Clusters []string `mapstructure:"Clusters" yaml:"Clusters,omitempty"`
 }
 
-func (c Configurations) IsClusterListConfigurationValid() bool {
-	valid := true
-	clusterNameMap := map[string]string{}
-	for _, cluster := range c.ClusterList {
-		if _, present := clusterNameMap[cluster.ClusterName]; present {
-			valid = false
-		}
-		clusterNameMap[cluster.ClusterName] = "present"
+type K8sObject struct {
+	DeploymentName string `mapstructure:"DeploymentName" yaml:"DeploymentName"`
+	ObjectType     string `mapstructure:"ObjectType" yaml:"ObjectType"`
+	ContainerName  string `mapstructure:"ContainerName" yaml:"ContainerName"`
+	Namespace      string `mapstructure:"Namespace" yaml:"Namespace"`
+}
 
-		clusterName := cluster.ClusterName == ""
-		awsRegion := cluster.AwsRegion == ""
-		awsAccount := cluster.AwsAccount == ""
-		awsNodeObject := cluster.AwsNodeObject.DeploymentName == "" || cluster.AwsNodeObject.ObjectType == "" || cluster.AwsNodeObject.ContainerName == "" || cluster.AwsNodeObject.Namespace == ""
-		clusterAutoscaler := cluster.ClusterAutoscalerObject.DeploymentName == "" || cluster.ClusterAutoscalerObject.ObjectType == "" || cluster.ClusterAutoscalerObject.ContainerName == "" || cluster.ClusterAutoscalerObject.Namespace == ""
-		coreDns := cluster.CoreDnsObject.DeploymentName == "" || cluster.CoreDnsObject.ObjectType == "" || cluster.CoreDnsObject.ContainerName == "" || cluster.CoreDnsObject.Namespace == ""
-		kubeProxy := cluster.KubeProxyObject.DeploymentName == "" || cluster.KubeProxyObject.ObjectType == "" || cluster.KubeProxyObject.ContainerName == "" || cluster.KubeProxyObject.Namespace == ""
+// ComponentVersionConfigurations maps the name of a component to the version it is expected to run with, which is
+// either a version the component needs to run exactly or a semantic version constraint like ">=1.10.1 <1.11"
+type ComponentVersionConfigurations map[string]string
 
-		if clusterName || awsRegion || awsAccount || awsNodeObject || clusterAutoscaler || coreDns || kubeProxy {
-			valid = false
-		}
+// Names returns the names of the components in alphabetical order
+func (c ComponentVersionConfigurations) Names() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MergedWith returns a copy of the component versions with the passed versions taking precedence
+func (c ComponentVersionConfigurations) MergedWith(overrides ComponentVersionConfigurations) ComponentVersionConfigurations {
+	merged := ComponentVersionConfigurations{}
+	for name, version := range c {
+		merged[name] = version
+	}
+	for name, version := range overrides {
+		merged[name] = version
 	}
-	return valid
+	return merged
+}
+
+func (c Configurations) IsClusterListConfigurationValid() bool {
+	return len(c.ValidateClusterListConfiguration()) == 0
 }
 
 func (c Configurations) IsComponentVersionConfigurationsValid() bool {
-	valid := true
-	if c.Components.CoreDns == "" || c.Components.AwsNode == "" || c.Components.ClusterAutoscaler == "" || c.Components.KubeProxy == "" {
-		valid = false
+	return len(c.ValidateComponentVersionConfigurations()) == 0
+}
+
+// allComponentVersions returns the top level component versions merged with the versions of every componentmatrix
+// row, which is every component a cluster can be expected to run
+func (c Configurations) allComponentVersions() ComponentVersionConfigurations {
+	componentVersions := c.Components.MergedWith(nil)
+	for _, row := range c.ComponentMatrix {
+		componentVersions = componentVersions.MergedWith(row)
 	}
-	return valid
+	return componentVersions
 }
 
 func (c Configurations) IsClusterNameValid(clusterName string) bool {
@@ -83,54 +155,153 @@ func (c Configurations) IsClusterNameValid(clusterName string) bool {
 	return contains
 }
 
-func (c Configurations) GetK8sObjectForCluster(clusterName, k8sObjectType string) (k8sObject K8sObject, err error) {
+// SelectClusters returns the names of the clusters matching the selector which are part of the group, in the order of
+// the clusterlist. An empty selector or group doesn't restrict the clusters
+func (c Configurations) SelectClusters(selector, group string) ([]string, error) {
+	var labelSelector Selector
+	var err error
+	if selector != "" {
+		labelSelector, err = ParseSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groupClusters := map[string]bool{}
+	if group != "" {
+		// viper lowercases the keys of the config file, which makes the group names case-insensitive
+		groupConfiguration, present := c.Groups[group]
+		if !present {
+			groupConfiguration, present = c.Groups[strings.ToLower(group)]
+		}
+		if !present {
+			groupNames := make([]string, 0, len(c.Groups))
+			for groupName := range c.Groups {
+				groupNames = append(groupNames, groupName)
+			}
+			sort.Strings(groupNames)
+			return nil, fmt.Errorf("please pass a valid group name from this list [%s]", strings.Join(groupNames, ", "))
+		}
+		groupClusters, err = c.groupClusters(groupConfiguration)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var clusterNames []string
+	for _, cluster := range c.ClusterList {
+		if group != "" && !groupClusters[cluster.ClusterName] {
+			continue
+		}
+		if labelSelector.Matches(cluster.Labels) {
+			clusterNames = append(clusterNames, cluster.ClusterName)
+		}
+	}
+	if len(clusterNames) == 0 {
+		return nil, errors.New("no cluster matches the selector and group passed")
+	}
+	return clusterNames, nil
+}
+
+// groupClusters returns the names of the clusters which are part of the group
+func (c Configurations) groupClusters(group GroupConfiguration) (map[string]bool, error) {
+	clusterNames := map[string]bool{}
+	for _, clusterName := range group.Clusters {
+		clusterNames[clusterName] = true
+	}
+	if group.Selector != "" {
+		selector, err := ParseSelector(group.Selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range c.ClusterList {
+			if selector.Matches(cluster.Labels) {
+				clusterNames[cluster.ClusterName] = true
+			}
+		}
+	}
+	return clusterNames, nil
+}
+
+// GetCluster returns the element of the clusterlist of the cluster, with the defaults and the overrides of the
+// environment variables applied
+func (c Configurations) GetCluster(clusterName string) (ClusterListConfiguration, error) {
+	for _, cluster := range c.ClusterList {
+		if cluster.ClusterName == clusterName {
+			return cluster, nil
+		}
+	}
+	return ClusterListConfiguration{}, errors.New("please check if you passed a valid cluster name")
+}
+
+// GetImageForCluster returns the image repository the component is expected to run from on the cluster, with its
+// placeholders replaced, and whether an image repository is configured for the component
+func (c Configurations) GetImageForCluster(clusterName, componentName string) (string, bool, error) {
+	image, present := c.Images[componentName]
+	if !present {
+		return "", false, nil
+	}
+	for _, cluster := range c.ClusterList {
+		if cluster.ClusterName == clusterName {
+			return c.ResolveImage(image, cluster)
+		}
+	}
+	return "", false, errors.New("please check if you passed a valid cluster name")
+}
+
+// ResolveImage replaces the {region} and {account} placeholders of the image repository with the AwsRegion and
+// AwsAccount of the cluster, and the {eks-registry} placeholder with the EKS registry of the AwsRegion
+func (c Configurations) ResolveImage(image string, cluster ClusterListConfiguration) (string, bool, error) {
+	eksRegistry := ""
+	if strings.Contains(image, "{eks-registry}") {
+		registry, err := c.GetEksRegistry(cluster.AwsRegion)
+		if err != nil {
+			return "", false, err
+		}
+		eksRegistry = registry
+	}
+	return strings.NewReplacer("{region}", cluster.AwsRegion, "{account}", cluster.AwsAccount,
+		"{eks-registry}", eksRegistry).Replace(image), true, nil
+}
+
+func (c Configurations) GetK8sObjectForCluster(clusterName, componentName string) (k8sObject K8sObject, err error) {
 	for _, cluster := range c.ClusterList {
 		if cluster.ClusterName == clusterName {
-			switch k8sObjectType {
-			case "aws-node":
-				return K8sObject{
-					DeploymentName: cluster.AwsNodeObject.DeploymentName,
-					ObjectType:     cluster.AwsNodeObject.ObjectType,
-					ContainerName:  cluster.AwsNodeObject.ContainerName,
-					Namespace:      cluster.AwsNodeObject.Namespace,
-				}, nil
-			case "cluster-autoscaler":
-				return K8sObject{
-					DeploymentName: cluster.ClusterAutoscalerObject.DeploymentName,
-					ObjectType:     cluster.ClusterAutoscalerObject.ObjectType,
-					ContainerName:  cluster.ClusterAutoscalerObject.ContainerName,
-					Namespace:      cluster.ClusterAutoscalerObject.Namespace,
-				}, nil
-			case "kube-proxy":
-				return K8sObject{
-					DeploymentName: cluster.KubeProxyObject.DeploymentName,
-					ObjectType:     cluster.KubeProxyObject.ObjectType,
-					ContainerName:  cluster.KubeProxyObject.ContainerName,
-					Namespace:      cluster.KubeProxyObject.Namespace,
-				}, nil
-			case "coredns":
-				return K8sObject{
-					DeploymentName: cluster.CoreDnsObject.DeploymentName,
-					ObjectType:     cluster.CoreDnsObject.ObjectType,
-					ContainerName:  cluster.CoreDnsObject.ContainerName,
-					Namespace:      cluster.CoreDnsObject.Namespace,
-				}, nil
-			default:
-				return K8sObject{
-					DeploymentName: "",
-					ObjectType:     "",
-					ContainerName:  "",
-					Namespace:      "",
-				}, errors.New("please pass any of the components between aws-node, coredns, cluster-autoscaler, kube-proxy")
+			if k8sObject, present := cluster.Components[componentName]; present {
+				return k8sObject, nil
+			}
+
+			names := make([]string, 0, len(cluster.Components))
+			for name := range cluster.Components {
+				names = append(names, name)
 			}
+			sort.Strings(names)
+			return K8sObject{}, fmt.Errorf("please pass any of the components between %s", strings.Join(names, ", "))
 		}
 	}
-	return K8sObject{
-		DeploymentName: "",
-		ObjectType:     "",
-		ContainerName:  "",
-		Namespace:      "",
-	}, errors.New("please check if you passed a valid cluster name")
+	return K8sObject{}, errors.New("please check if you passed a valid cluster name")
+}
+
+// GetKubeContextForCluster returns the kube context of the cluster, which is its ClusterName unless a KubeContext is
+// set, along with the path of the kubeconfig to read it from, which is empty when none is set. A KubeconfigPath
+// starting with ~/ is relative to the home directory
+func (c Configurations) GetKubeContextForCluster(clusterName string) (kubeContext, kubeconfigPath string, err error) {
+	cluster, err := c.GetCluster(clusterName)
+	if err != nil {
+		return "", "", err
+	}
+	kubeContext, kubeconfigPath = cluster.KubeContext, cluster.KubeconfigPath
+	if kubeContext == "" {
+		kubeContext = cluster.ClusterName
+	}
+	if strings.HasPrefix(kubeconfigPath, "~/") {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", "", fmt.Errorf("error expanding the KubeconfigPath %s: %s", kubeconfigPath, err)
+		}
+		kubeconfigPath = filepath.Join(homeDir, strings.TrimPrefix(kubeconfigPath, "~/"))
+	}
+	return kubeContext, kubeconfigPath, nil
 }
 
 func (c Configurations) GetAwsAccountAndRegionForCluster(clusterName string) (awsAccount, awsRegion string, err error) {
@@ -142,61 +313,356 @@ func (c Configurations) GetAwsAccountAndRegionForCluster(clusterName string) (aw
 	return "", "", errors.New("no awsAccount and awsRegion was found for the passed clusterName")
 }
 
-func (c Configurations) ValidatePassedComponentVersions(componentName, componentVersion string) error {
-	switch componentName {
-	case "aws-node":
-		if componentVersion != c.Components.AwsNode {
-			return errors.New("aws-node component version passed doesn't match the version in config, please check the value in config file")
+// HasComponentMatrix returns whether the component versions depend on the kubernetes version a cluster runs on
+func (c Configurations) HasComponentMatrix() bool {
+	return len(c.ComponentMatrix) != 0
+}
+
+// GetComponentMatrixRow returns the componentmatrix row for the passed kubernetes minor version, for example "1.27"
+func (c Configurations) GetComponentMatrixRow(kubernetesMinorVersion string) (ComponentVersionConfigurations, error) {
+	row, present := c.ComponentMatrix[kubernetesMinorVersion]
+	if !present {
+		rows := make([]string, 0, len(c.ComponentMatrix))
+		for minorVersion := range c.ComponentMatrix {
+			rows = append(rows, minorVersion)
 		}
-	case "cluster-autoscaler":
-		if componentVersion != c.Components.ClusterAutoscaler {
-			return errors.New("cluster-autoscaler component version passed doesn't match the version in config, please check the value in config file")
+		sort.Strings(rows)
+		return ComponentVersionConfigurations{}, fmt.Errorf("the componentmatrix has no row for kubernetes version %s, rows present: [%s]",
+			kubernetesMinorVersion, strings.Join(rows, ", "))
+	}
+	return row, nil
+}
+
+// GetComponentVersionsForCluster returns the versions the components of the cluster are expected to run with, which
+// are the versions under the top level components key, overridden by the componentmatrix row of the kubernetes minor
+// version the cluster runs on and then by the ComponentVersions of the cluster. The kubernetes minor version is only
+// used when a componentmatrix is configured
+func (c Configurations) GetComponentVersionsForCluster(clusterName, kubernetesMinorVersion string) (ComponentVersionConfigurations, error) {
+	for _, cluster := range c.ClusterList {
+		if cluster.ClusterName == clusterName {
+			componentVersions := c.Components.MergedWith(nil)
+			if c.HasComponentMatrix() {
+				row, err := c.GetComponentMatrixRow(kubernetesMinorVersion)
+				if err != nil {
+					return ComponentVersionConfigurations{}, err
+				}
+				componentVersions = componentVersions.MergedWith(row)
+			}
+			return componentVersions.MergedWith(cluster.ComponentVersions), nil
 		}
-	case "kube-proxy":
-		if componentVersion != c.Components.KubeProxy {
-			return errors.New("kube-proxy component version passed doesn't match the version in config, please check the value in config file")
+	}
+	return ComponentVersionConfigurations{}, errors.New("please check if you passed a valid cluster name")
+}
+
+func (c Configurations) ValidatePassedComponentVersions(clusterName, kubernetesMinorVersion, componentName, componentVersion string) error {
+	componentVersions, err := c.GetComponentVersionsForCluster(clusterName, kubernetesMinorVersion)
+	if err != nil {
+		return err
+	}
+
+	version, present := componentVersions[componentName]
+	if !present {
+		return fmt.Errorf("please pass a valid component name from this list [%s]", strings.Join(componentVersions.Names(), ", "))
+	}
+	if semver.IsConstraint(version) {
+		result, err := semver.Check(componentVersion, version)
+		if err != nil {
+			return err
 		}
-	case "coredns":
-		if componentVersion != c.Components.CoreDns {
-			return errors.New("coredns component version passed doesn't match the version in config, please check the value in config file")
+		if result != semver.Satisfies {
+			return fmt.Errorf("%s component version passed doesn't satisfy the constraint %s in config for cluster %s, please check the value in config file", componentName, version, clusterName)
 		}
-	default:
-		return errors.New("please pass a valid component name from this list [coredns, cluster-autoscaler, kube-proxy, aws-node]")
+	} else if componentVersion != version {
+		return fmt.Errorf("%s component version passed doesn't match the version in config for cluster %s, please check the value in config file", componentName, clusterName)
 	}
 
 	return nil
 }
 
-func Read(fileName, fileType, filePath string) (config Configurations, err error) {
-	viper.SetConfigName(fileName)
-	viper.SetConfigType(fileType)
-	viper.AddConfigPath(filePath)
-	err = viper.ReadInConfig()
+// Loader locates and reads config files. Every read uses a viper instance of its own, so several config files can be
+// read in one process and no package level state is touched. The zero value looks the config file up like the tool does
+type Loader struct {
+	// Getenv looks up the environment variables the config file location depends on, os.Getenv when nil
+	Getenv func(key string) string
+	// Fetchers fetch config files from remote locations by URL scheme, http and https URLs are fetched with an
+	// HTTPFetcher when no fetcher is set for them
+	Fetchers map[string]RemoteFetcher
+	// CacheDir is the directory config files fetched from remote locations are cached in, the
+	// k8s-cluster-upgrade-tool directory of the XDG cache directory when empty
+	CacheDir string
+	// Sha256 is the SHA-256 checksum a config file fetched from a remote location needs to match, the one set in
+	// K8S_CLUSTER_UPGRADE_TOOL_CONFIG_SHA256 when empty
+	Sha256 string
+}
+
+// Read reads the config file fileName.fileType of the directory filePath with a zero Loader
+func Read(fileName, fileType, filePath string) (Configurations, error) {
+	return Loader{}.Read(fileName, fileType, filePath)
+}
+
+// Load reads the config file at the path returned by Locate for the passed path. The Configurations returned are
+// fully populated, including the absolute path of the config file read, and aren't modified afterwards. When the path
+// is the URL of a remote location, the config file is fetched into the cache and the cached copy is read
+func (l Loader) Load(path string) (Configurations, error) {
+	path, err := l.Locate(path)
+	if err != nil {
+		return Configurations{}, err
+	}
+	if !IsRemote(path) {
+		return l.Read(FileMetadataForPath(path))
+	}
+
+	cachedPath, source, commit, err := l.fetchRemote(path)
+	if err != nil {
+		return Configurations{}, err
+	}
+	config, err := l.Read(FileMetadataForPath(cachedPath))
+	if err != nil {
+		return Configurations{}, err
+	}
+	err = commit()
+	if err != nil {
+		return Configurations{}, err
+	}
+	config.Remote = &source
+	return config, nil
+}
+
+// Read reads the config file fileName.fileType of the directory filePath along with the clusters of the clusters.d
+// directory next to it, applies the defaults and the overrides of the environment variables and validates them
+func (l Loader) Read(fileName, fileType, filePath string) (config Configurations, err error) {
+	v := viper.New()
+	v.SetConfigName(fileName)
+	v.SetConfigType(fileType)
+	v.AddConfigPath(filePath)
+	err = v.ReadInConfig()
 	if err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			return Configurations{}, errors.New("error finding config file. Does it exist? Please create it in $HOME/.k8s-cluster-upgrade-tool/config.yaml if not")
+			return Configurations{}, fmt.Errorf("error finding config file. Does it exist? Please create it in %s if not",
+				filepath.Join(filePath, fileName+"."+fileType))
 		} else {
 			return Configurations{}, errors.New("error reading from config file")
 		}
 	}
 
-	err = viper.Unmarshal(&config)
+	// config files of older schema versions are migrated before they are unmarshalled
+	data, err := ioutil.ReadFile(v.ConfigFileUsed())
+	if err != nil {
+		return Configurations{}, errors.New("error reading from config file")
+	}
+	migrated, fromVersion, err := Migrate(data)
+	if err != nil {
+		return Configurations{}, err
+	}
+	if fromVersion != CurrentVersion {
+		err = v.ReadConfig(bytes.NewReader(migrated))
+		if err != nil {
+			return Configurations{}, errors.New("error reading from config file")
+		}
+		config.MigratedFromVersion = fromVersion
+	}
+
+	err = v.Unmarshal(&config)
 	if err != nil {
 		return Configurations{}, errors.New("error un marshaling config file")
 	}
+	err = mapstructure.Decode(v.Get("componentmatrix"), &config.ComponentMatrix)
+	if err != nil {
+		return Configurations{}, errors.New("error un marshaling componentmatrix in config file")
+	}
 
-	// check for the mandatory config file variables being read
-	if !config.IsComponentVersionConfigurationsValid() {
-		return Configurations{}, errors.New("mandatory component version of either aws-node, coredns, kube-proxy or cluster-autoscaler not set in config file")
+	config.File, err = filepath.Abs(v.ConfigFileUsed())
+	if err != nil {
+		return Configurations{}, errors.New("error resolving the path of the config file")
+	}
+	for index := range config.ClusterList {
+		config.ClusterList[index].Source = ClusterSource{File: config.File, Index: index}
+	}
+	clusters, err := readClustersDir(filepath.Join(filepath.Dir(config.File), ClustersDirName))
+	if err != nil {
+		return Configurations{}, err
 	}
+	config.ClusterList = append(config.ClusterList, clusters...)
+	config.lowercaseComponentNames()
+	config.applyDefaults()
+	validationErrors := validateComponentMatrixKeys(migrated)
+	validationErrors = append(validationErrors, config.applyEnvironmentOverrides(l.getenv)...)
 
-	if !config.IsClusterListConfigurationValid() {
-		return Configurations{}, errors.New("one of the clusterlist elements has either ClusterName, AwsRegion, AwsAccount, AwsNodeObject, ClusterAutoscalerObject, KubeProxyObject, CoreDnsObject is missing")
+	// check for the mandatory config file variables being read
+	validationErrors = append(validationErrors, config.Validate()...)
+	if len(validationErrors) != 0 {
+		return Configurations{}, validationErrors
 	}
 
 	return config, nil
 }
 
-func FileMetadata() (fileName, filePath, fileType string) {
+// RelativePath returns the passed path relative to the directory of the config file when possible, which is how the
+// files of the clusters.d directory are shown
+func (c Configurations) RelativePath(path string) string {
+	if c.File != "" {
+		if relativePath, err := filepath.Rel(filepath.Dir(c.File), path); err == nil {
+			return relativePath
+		}
+	}
+	return path
+}
+
+// readClustersDir returns the clusterlist elements of the YAML files in the passed directory, read in the order of their
+// names. Every file has a clusterlist key like the config file, other keys are ignored
+func readClustersDir(dir string) ([]ClusterListConfiguration, error) {
+	paths, err := clustersDirFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []ClusterListConfiguration
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading from clusters file %s", path)
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		migrated, _, err := Migrate(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		// the file is decoded by viper like the config file, so its keys and values are read the same way
+		var clustersFile struct {
+			ClusterList []ClusterListConfiguration `mapstructure:"clusterlist"`
+		}
+		v := viper.New()
+		v.SetConfigType(FileType)
+		err = v.ReadConfig(bytes.NewReader(migrated))
+		if err == nil {
+			err = v.Unmarshal(&clustersFile)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error un marshaling clusters file %s", path)
+		}
+
+		for index, cluster := range clustersFile.ClusterList {
+			cluster.Source = ClusterSource{File: path, Index: index}
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+// lowercaseComponentNames lowercases the component names of the Components and ComponentVersions of the clusters. viper
+// lowercases the keys of the maps of the config file, which it doesn't do for the maps of the clusterlist elements, so a
+// component configured as CoreDNS is the coredns component everywhere
+func (c *Configurations) lowercaseComponentNames() {
+	for index := range c.ClusterList {
+		cluster := &c.ClusterList[index]
+		if cluster.Components != nil {
+			components := make(map[string]K8sObject, len(cluster.Components))
+			for componentName, k8sObject := range cluster.Components {
+				components[strings.ToLower(componentName)] = k8sObject
+			}
+			cluster.Components = components
+		}
+		if cluster.ComponentVersions != nil {
+			componentVersions := make(ComponentVersionConfigurations, len(cluster.ComponentVersions))
+			for componentName, version := range cluster.ComponentVersions {
+				componentVersions[strings.ToLower(componentName)] = version
+			}
+			cluster.ComponentVersions = componentVersions
+		}
+	}
+}
+
+// ClustersFiles returns the paths of the YAML files of the clusters.d directory next to the config file, in the order
+// of their names
+func ClustersFiles(configFile string) ([]string, error) {
+	return clustersDirFiles(filepath.Join(filepath.Dir(configFile), ClustersDirName))
+}
+
+func clustersDirFiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading from the clusters directory %s", dir)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		extension := filepath.Ext(entry.Name())
+		if entry.IsDir() || (extension != ".yaml" && extension != ".yml") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	return paths, nil
+}
+
+func FileMetadata() (fileName, fileType, filePath string) {
 	return FileName, FileType, FilePath
 }
+
+// SearchPaths returns the directories the config file is looked up in, in the order they are searched: the
+// .k8s-cluster-upgrade-tool directory of the current directory, the k8s-cluster-upgrade-tool directory of the XDG config
+// directory, which defaults to $HOME/.config, and the .k8s-cluster-upgrade-tool directory of the home directory
+func SearchPaths() []string {
+	return Loader{}.SearchPaths()
+}
+
+// SearchPaths returns the directories the config file is looked up in, with the environment variables of the Loader
+func (l Loader) SearchPaths() []string {
+	xdgConfigHome := l.getenv("XDG_CONFIG_HOME")
+	if xdgConfigHome == "" {
+		xdgConfigHome = filepath.Join(l.getenv("HOME"), ".config")
+	}
+	return []string{
+		".k8s-cluster-upgrade-tool",
+		filepath.Join(xdgConfigHome, "k8s-cluster-upgrade-tool"),
+		os.Expand(FilePath, l.getenv),
+	}
+}
+
+func (l Loader) getenv(key string) string {
+	if l.Getenv == nil {
+		return os.Getenv(key)
+	}
+	return l.Getenv(key)
+}
+
+// Locate returns the path of the config file to read, which is the path passed when it's not empty, the path set in
+// the K8S_CLUSTER_UPGRADE_TOOL_CONFIG environment variable, or the first config.yaml found in the SearchPaths
+func Locate(path string) (string, error) {
+	return Loader{}.Locate(path)
+}
+
+// Locate returns the path of the config file to read, with the environment variables of the Loader
+func (l Loader) Locate(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if path := l.getenv(FileEnvironmentVariable); path != "" {
+		return path, nil
+	}
+
+	fileName, fileType, _ := FileMetadata()
+	var candidates []string
+	for _, searchPath := range l.SearchPaths() {
+		candidate := filepath.Join(searchPath, fileName+"."+fileType)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+		candidates = append(candidates, candidate)
+	}
+	return "", fmt.Errorf("error finding config file. Does it exist? Please create it in one of %s, or pass its path with --config or %s",
+		strings.Join(candidates, ", "), FileEnvironmentVariable)
+}
+
+// FileMetadataForPath returns the metadata needed to read the config file at the passed path
+func FileMetadataForPath(path string) (fileName, fileType, filePath string) {
+	fileType = strings.TrimPrefix(filepath.Ext(path), ".")
+	fileName = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return fileName, fileType, filepath.Dir(path)
+}
@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -24,58 +25,62 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 						ClusterName: "cluster1",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 					{
 						ClusterName: "cluster2",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -90,58 +95,62 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 						ClusterName: "cluster1",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 					{
 						ClusterName: "cluster2",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -151,28 +160,34 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 		{
 			name: "when the config passed has one of the k8sObjects keys missing",
 			configuration: Configurations{
+				Components: ComponentVersionConfigurations{
+					"aws-node": "aws-node-version", "cluster-autoscaler": "cluster-autoscaler-version",
+					"coredns": "core-dns-version", "kube-proxy": "kube-proxy-version",
+				},
 				ClusterList: []ClusterListConfiguration{
 					{
 						ClusterName: "cluster2",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -180,35 +195,133 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 			result: false,
 		},
 		{
-			name: "when the config passed has one of the attributes of k8sObject attribute missing",
+			name: "when the config passed has the objects of any components configured",
+			configuration: Configurations{
+				Components: ComponentVersionConfigurations{"metrics-server": "metrics-server-version", "coredns": "core-dns-version"},
+				ClusterList: []ClusterListConfiguration{
+					{
+						ClusterName: "cluster1",
+						AwsRegion:   "region",
+						AwsAccount:  "account",
+						Components: map[string]K8sObject{
+							"metrics-server": {
+								DeploymentName: "metrics-server",
+								ObjectType:     "deployment",
+								ContainerName:  "metrics-server",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "coredns",
+								Namespace:      "kube-system",
+							},
+						},
+					},
+				},
+			},
+			result: true,
+		},
+		{
+			name: "when the config passed has a component configured with the Components key which is missing in a cluster",
 			configuration: Configurations{
+				Components: ComponentVersionConfigurations{"metrics-server": "metrics-server-version"},
 				ClusterList: []ClusterListConfiguration{
 					{
 						ClusterName: "cluster1",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"ingress-nginx": {
+								DeploymentName: "ingress-nginx-controller",
+								ObjectType:     "deployment",
+								ContainerName:  "controller",
+								Namespace:      "ingress-nginx",
+							},
 						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+					},
+				},
+			},
+			result: false,
+		},
+		{
+			name: "when the config passed overrides the version of a component for a cluster which has no object for it",
+			configuration: Configurations{
+				Components: ComponentVersionConfigurations{"coredns": "core-dns-version"},
+				ClusterList: []ClusterListConfiguration{
+					{
+						ClusterName:       "cluster1",
+						AwsRegion:         "region",
+						AwsAccount:        "account",
+						ComponentVersions: ComponentVersionConfigurations{"metrics-server": "metrics-server-version"},
+						Components: map[string]K8sObject{
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "coredns",
+								Namespace:      "kube-system",
+							},
 						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+					},
+				},
+			},
+			result: false,
+		},
+		{
+			name: "when the config passed overrides the version of a component for a cluster with an empty string",
+			configuration: Configurations{
+				Components: ComponentVersionConfigurations{"coredns": "core-dns-version"},
+				ClusterList: []ClusterListConfiguration{
+					{
+						ClusterName:       "cluster1",
+						AwsRegion:         "region",
+						AwsAccount:        "account",
+						ComponentVersions: ComponentVersionConfigurations{"coredns": ""},
+						Components: map[string]K8sObject{
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "coredns",
+								Namespace:      "kube-system",
+							},
 						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+					},
+				},
+			},
+			result: false,
+		},
+		{
+			name: "when the config passed has one of the attributes of k8sObject attribute missing",
+			configuration: Configurations{
+				ClusterList: []ClusterListConfiguration{
+					{
+						ClusterName: "cluster1",
+						AwsRegion:   "region",
+						AwsAccount:  "account",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -222,52 +335,56 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 					{
 						AwsRegion:  "region",
 						AwsAccount: "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 					{
 						ClusterName: "cluster2",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -281,58 +398,62 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 					{
 						ClusterName: "cluster-1",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 					{
 						ClusterName: "cluster2",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -346,58 +467,62 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 					{
 						ClusterName: "cluster-1",
 						AwsRegion:   "region",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 					{
 						ClusterName: "cluster2",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -412,58 +537,62 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 						ClusterName: "cluster1",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 					{
 						ClusterName: "cluster1",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "container-name",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "container-name",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -479,6 +608,14 @@ func TestConfigurations_IsClusterListConfigurationValid(t *testing.T) {
 	}
 }
 
+func TestComponentVersionConfigurations_Names(t *testing.T) {
+	t.Run("returns the component names in alphabetical order", func(t *testing.T) {
+		components := ComponentVersionConfigurations{"kube-proxy": "v1", "aws-node": "v2", "metrics-server": "v3"}
+
+		assert.Equal(t, []string{"aws-node", "kube-proxy", "metrics-server"}, components.Names())
+	})
+}
+
 func TestConfigurations_IsClusterNameValid(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -593,11 +730,60 @@ func TestConfigurations_GetAwsAccountAndRegionForCluster(t *testing.T) {
 	}
 }
 
+func TestConfigurations_GetKubeContextForCluster(t *testing.T) {
+	homeDir, err := os.UserHomeDir()
+	assert.Nil(t, err)
+	configuration := Configurations{ClusterList: []ClusterListConfiguration{
+		{ClusterName: "cluster1"},
+		{ClusterName: "cluster2", KubeContext: "arn:aws:eks:eu-west-1:123456789012:cluster/cluster2", KubeconfigPath: "/kube/config"},
+		{ClusterName: "cluster3", KubeconfigPath: "~/.kube/cluster3"},
+	}}
+
+	tests := []struct {
+		name               string
+		clusterName        string
+		kubeContext        string
+		kubeconfigPath     string
+		expectedErrMessage string
+	}{
+		{"when no KubeContext is set it returns the ClusterName", "cluster1", "cluster1", "", ""},
+		{"when a KubeContext and KubeconfigPath are set it returns them", "cluster2",
+			"arn:aws:eks:eu-west-1:123456789012:cluster/cluster2", "/kube/config", ""},
+		{"when the KubeconfigPath starts with ~/ it is expanded to the home directory", "cluster3", "cluster3",
+			filepath.Join(homeDir, ".kube/cluster3"), ""},
+		{"when the cluster is not found it returns an error", "cluster4", "", "", "please check if you passed a valid cluster name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeContext, kubeconfigPath, err := configuration.GetKubeContextForCluster(tt.clusterName)
+
+			if tt.expectedErrMessage != "" {
+				assert.EqualError(t, err, tt.expectedErrMessage)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, tt.kubeContext, kubeContext)
+			assert.Equal(t, tt.kubeconfigPath, kubeconfigPath)
+		})
+	}
+}
+
 func TestConfigurations_ValidatePassedComponentVersions(t *testing.T) {
 	type testArgs struct {
+		clusterName      string
 		componentName    string
 		componentVersion string
 	}
+	configuration := Configurations{
+		Components: ComponentVersionConfigurations{
+			"coredns": "rightvalue", "cluster-autoscaler": "rightvalue", "kube-proxy": "rightvalue", "aws-node": "rightvalue"},
+		ClusterList: []ClusterListConfiguration{
+			{ClusterName: "cluster1"},
+			{ClusterName: "cluster2", ComponentVersions: ComponentVersionConfigurations{"coredns": "overriddenvalue"}},
+			{ClusterName: "cluster3", ComponentVersions: ComponentVersionConfigurations{"coredns": ">=1.10.1 <1.11"}},
+		},
+	}
 	tests := []struct {
 		name   string
 		config Configurations
@@ -605,37 +791,134 @@ func TestConfigurations_ValidatePassedComponentVersions(t *testing.T) {
 		err    error
 	}{
 		{"when passed component version name is valid and the version to be set matches the config file",
-			Configurations{
-				Components: ComponentVersionConfigurations{
-					CoreDns: "rightvalue", ClusterAutoscaler: "rightvalue", KubeProxy: "rightvalue", AwsNode: "rightvalue"}},
-			testArgs{componentName: "coredns", componentVersion: "rightvalue"},
+			configuration,
+			testArgs{clusterName: "cluster1", componentName: "coredns", componentVersion: "rightvalue"},
 			nil,
 		},
 		{"when passed component version name is valid and the version to be set doesn't match the config file",
-			Configurations{
-				Components: ComponentVersionConfigurations{
-					CoreDns: "rightvalue", ClusterAutoscaler: "rightvalue", KubeProxy: "rightvalue", AwsNode: "rightvalue"}},
-			testArgs{componentName: "coredns", componentVersion: "wrongvalue"},
-			errors.New("coredns component version passed doesn't match the version in config, please check the value in config file"),
+			configuration,
+			testArgs{clusterName: "cluster1", componentName: "coredns", componentVersion: "wrongvalue"},
+			errors.New("coredns component version passed doesn't match the version in config for cluster cluster1, please check the value in config file"),
+		},
+		{"when passed component version matches the version overridden for the cluster",
+			configuration,
+			testArgs{clusterName: "cluster2", componentName: "coredns", componentVersion: "overriddenvalue"},
+			nil,
+		},
+		{"when passed component version satisfies the constraint in the config file",
+			configuration,
+			testArgs{clusterName: "cluster3", componentName: "coredns", componentVersion: "v1.10.1-eksbuild.3"},
+			nil,
+		},
+		{"when passed component version doesn't satisfy the constraint in the config file",
+			configuration,
+			testArgs{clusterName: "cluster3", componentName: "coredns", componentVersion: "v1.11.0-eksbuild.1"},
+			errors.New("coredns component version passed doesn't satisfy the constraint >=1.10.1 <1.11 in config for cluster cluster3, please check the value in config file"),
+		},
+		{"when passed component version matches the top level version, but it is overridden for the cluster",
+			configuration,
+			testArgs{clusterName: "cluster2", componentName: "coredns", componentVersion: "rightvalue"},
+			errors.New("coredns component version passed doesn't match the version in config for cluster cluster2, please check the value in config file"),
 		},
 		{"when passed component version is not valid",
-			Configurations{
-				Components: ComponentVersionConfigurations{
-					CoreDns: "rightvalue", ClusterAutoscaler: "rightvalue", KubeProxy: "rightvalue", AwsNode: "rightvalue"}},
-			testArgs{componentName: "foo", componentVersion: "wrongvalue"},
-			errors.New("please pass a valid component name from this list [coredns, cluster-autoscaler, kube-proxy, aws-node]"),
+			configuration,
+			testArgs{clusterName: "cluster1", componentName: "foo", componentVersion: "wrongvalue"},
+			errors.New("please pass a valid component name from this list [aws-node, cluster-autoscaler, coredns, kube-proxy]"),
+		},
+		{"when passed cluster name is not valid",
+			configuration,
+			testArgs{clusterName: "foo", componentName: "coredns", componentVersion: "rightvalue"},
+			errors.New("please check if you passed a valid cluster name"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := tt.config.ValidatePassedComponentVersions(tt.args.componentName, tt.args.componentVersion)
+			err := tt.config.ValidatePassedComponentVersions(tt.args.clusterName, "", tt.args.componentName, tt.args.componentVersion)
 
 			assert.Equal(t, err, tt.err)
 		})
 	}
 }
 
+func TestConfigurations_GetComponentVersionsForCluster(t *testing.T) {
+	configuration := Configurations{
+		Components: ComponentVersionConfigurations{"coredns": "v1.8.4", "kube-proxy": "v1.21.2"},
+		ClusterList: []ClusterListConfiguration{
+			{ClusterName: "cluster1"},
+			{ClusterName: "cluster2", ComponentVersions: ComponentVersionConfigurations{"coredns": "v1.8.7", "metrics-server": "v0.6.1"}},
+		},
+	}
+	tests := []struct {
+		name        string
+		clusterName string
+		want        ComponentVersionConfigurations
+		err         error
+	}{
+		{"returns the top level component versions when the cluster has no overrides",
+			"cluster1", ComponentVersionConfigurations{"coredns": "v1.8.4", "kube-proxy": "v1.21.2"}, nil},
+		{"returns the top level component versions overridden by the versions of the cluster",
+			"cluster2", ComponentVersionConfigurations{"coredns": "v1.8.7", "kube-proxy": "v1.21.2", "metrics-server": "v0.6.1"}, nil},
+		{"returns an error when the cluster is not present",
+			"cluster3", ComponentVersionConfigurations{}, errors.New("please check if you passed a valid cluster name")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := configuration.GetComponentVersionsForCluster(tt.clusterName, "")
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	t.Run("does not modify the top level component versions", func(t *testing.T) {
+		_, _ = configuration.GetComponentVersionsForCluster("cluster2", "")
+
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.4", "kube-proxy": "v1.21.2"}, configuration.Components)
+	})
+}
+
+func TestConfigurations_GetComponentVersionsForCluster_WithComponentMatrix(t *testing.T) {
+	configuration := Configurations{
+		Components: ComponentVersionConfigurations{"cluster-autoscaler": "v1.20.0"},
+		ComponentMatrix: map[string]ComponentVersionConfigurations{
+			"1.26": {"coredns": "v1.9.3-eksbuild.2", "kube-proxy": "v1.26.2-eksbuild.1"},
+			"1.27": {"coredns": "v1.10.1-eksbuild.2", "kube-proxy": "v1.27.1-eksbuild.1", "cluster-autoscaler": "v1.27.2"},
+		},
+		ClusterList: []ClusterListConfiguration{
+			{ClusterName: "cluster1"},
+			{ClusterName: "cluster2", ComponentVersions: ComponentVersionConfigurations{"coredns": "v1.10.1-eksbuild.1"}},
+		},
+	}
+	tests := []struct {
+		name                   string
+		clusterName            string
+		kubernetesMinorVersion string
+		want                   ComponentVersionConfigurations
+		err                    error
+	}{
+		{"returns the top level component versions overridden by the row of the kubernetes version",
+			"cluster1", "1.26",
+			ComponentVersionConfigurations{"coredns": "v1.9.3-eksbuild.2", "kube-proxy": "v1.26.2-eksbuild.1", "cluster-autoscaler": "v1.20.0"}, nil},
+		{"returns the row of the kubernetes version overridden by the versions of the cluster",
+			"cluster2", "1.27",
+			ComponentVersionConfigurations{"coredns": "v1.10.1-eksbuild.1", "kube-proxy": "v1.27.1-eksbuild.1", "cluster-autoscaler": "v1.27.2"}, nil},
+		{"returns an error when the kubernetes version has no row",
+			"cluster1", "1.28",
+			ComponentVersionConfigurations{}, errors.New("the componentmatrix has no row for kubernetes version 1.28, rows present: [1.26, 1.27]")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := configuration.GetComponentVersionsForCluster(tt.clusterName, tt.kubernetesMinorVersion)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestConfigurations_IsComponentVersionConfigurationsValid(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -646,25 +929,68 @@ func TestConfigurations_IsComponentVersionConfigurationsValid(t *testing.T) {
 			name: "when all the passed component version configurations are present",
 			configuration: Configurations{
 				Components: ComponentVersionConfigurations{
-					CoreDns:           "core-dns-version",
-					AwsNode:           "aws-node-version",
-					ClusterAutoscaler: "cluster-autoscaler-version",
-					KubeProxy:         "kube-proxy-version",
+					"coredns":            "core-dns-version",
+					"aws-node":           "aws-node-version",
+					"cluster-autoscaler": "cluster-autoscaler-version",
+					"kube-proxy":         "kube-proxy-version",
 				},
 			},
 			result: true,
 		},
 		{
-			name: "when one of the required component keys are not passed",
+			name: "when components other than aws-node, coredns, cluster-autoscaler and kube-proxy are configured",
 			configuration: Configurations{
 				Components: ComponentVersionConfigurations{
-					AwsNode:           "aws-node-version",
-					ClusterAutoscaler: "cluster-autoscaler-version",
-					KubeProxy:         "kube-proxy-version",
+					"metrics-server": "metrics-server-version",
+					"cert-manager":   "cert-manager-version",
+				},
+			},
+			result: true,
+		},
+		{
+			name: "when one of the component versions is an empty string",
+			configuration: Configurations{
+				Components: ComponentVersionConfigurations{
+					"coredns":            "",
+					"aws-node":           "aws-node-version",
+					"cluster-autoscaler": "cluster-autoscaler-version",
+					"kube-proxy":         "kube-proxy-version",
+				},
+			},
+			result: false,
+		},
+		{
+			name: "when only a componentmatrix is configured",
+			configuration: Configurations{
+				ComponentMatrix: map[string]ComponentVersionConfigurations{
+					"1.27": {"coredns": "v1.10.1-eksbuild.2"},
+				},
+			},
+			result: true,
+		},
+		{
+			name: "when a componentmatrix row is not a kubernetes minor version",
+			configuration: Configurations{
+				ComponentMatrix: map[string]ComponentVersionConfigurations{
+					"1.27.1": {"coredns": "v1.10.1-eksbuild.2"},
+				},
+			},
+			result: false,
+		},
+		{
+			name: "when a componentmatrix row has an empty component version",
+			configuration: Configurations{
+				ComponentMatrix: map[string]ComponentVersionConfigurations{
+					"1.27": {"coredns": ""},
 				},
 			},
 			result: false,
 		},
+		{
+			name:          "when no component versions are configured",
+			configuration: Configurations{},
+			result:        false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -674,6 +1000,40 @@ func TestConfigurations_IsComponentVersionConfigurationsValid(t *testing.T) {
 	}
 }
 
+func TestConfigurations_GetImageForCluster(t *testing.T) {
+	configuration := Configurations{
+		ClusterList: []ClusterListConfiguration{{ClusterName: "cluster1", AwsRegion: "eu-west-1", AwsAccount: "602401143452"}},
+		Images: map[string]string{
+			"coredns":  "{account}.dkr.ecr.{region}.amazonaws.com/eks/coredns",
+			"aws-node": "{eks-registry}/amazon-k8s-cni",
+		},
+	}
+	tests := []struct {
+		name          string
+		clusterName   string
+		componentName string
+		want          string
+		wantPresent   bool
+		err           error
+	}{
+		{"when an image repository is configured it returns it with the placeholders replaced", "cluster1", "coredns",
+			"602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/coredns", true, nil},
+		{"when an image repository uses the EKS registry it returns it with the EKS registry of the region", "cluster1", "aws-node",
+			"602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni", true, nil},
+		{"when no image repository is configured it returns that none is configured", "cluster1", "kube-proxy", "", false, nil},
+		{"when the cluster name is not valid it returns an error", "cluster2", "coredns", "", false,
+			errors.New("please check if you passed a valid cluster name")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotPresent, err := configuration.GetImageForCluster(tt.clusterName, tt.componentName)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPresent, gotPresent)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
 func TestConfigurations_GetK8sObjectForCluster(t *testing.T) {
 	type result struct {
 		DeploymentName, ObjectType, ContainerName, Namespace string
@@ -693,58 +1053,62 @@ func TestConfigurations_GetK8sObjectForCluster(t *testing.T) {
 						ClusterName: "cluster1",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "aws-node",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "aws-cluster-autoscaler",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "kube-proxy",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "core-dns",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "aws-node",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "aws-cluster-autoscaler",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "kube-proxy",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "core-dns",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 					{
 						ClusterName: "cluster2",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-							ContainerName:  "aws-node",
-							Namespace:      "kube-system",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-							ContainerName:  "aws-cluster-autoscaler",
-							Namespace:      "kube-system",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-							ContainerName:  "kube-proxy",
-							Namespace:      "kube-system",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
-							ContainerName:  "core-dns",
-							Namespace:      "kube-system",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+								ContainerName:  "aws-node",
+								Namespace:      "kube-system",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+								ContainerName:  "aws-cluster-autoscaler",
+								Namespace:      "kube-system",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+								ContainerName:  "kube-proxy",
+								Namespace:      "kube-system",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+								ContainerName:  "core-dns",
+								Namespace:      "kube-system",
+							},
 						},
 					},
 				},
@@ -760,28 +1124,59 @@ func TestConfigurations_GetK8sObjectForCluster(t *testing.T) {
 			expectedErr: nil,
 		},
 		{
-			name: "when the cluster name is present and the k8sobject passed is invalid",
+			name: "when the cluster name is present and the component is configured with the Components key",
 			configuration: Configurations{
 				ClusterList: []ClusterListConfiguration{
 					{
 						ClusterName: "cluster1",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
+						Components: map[string]K8sObject{
+							"ebs-csi-driver": {
+								DeploymentName: "ebs-csi-controller",
+								ObjectType:     "deployment",
+								ContainerName:  "ebs-plugin",
+								Namespace:      "kube-system",
+							},
 						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
+					},
+				},
+			},
+			clusterNameArg: "cluster1",
+			k8sObjectArg:   "ebs-csi-driver",
+			expectedResult: result{
+				DeploymentName: "ebs-csi-controller",
+				ObjectType:     "deployment",
+				ContainerName:  "ebs-plugin",
+				Namespace:      "kube-system",
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "when the cluster name is present and the k8sobject passed is invalid",
+			configuration: Configurations{
+				ClusterList: []ClusterListConfiguration{
+					{
+						ClusterName: "cluster1",
+						AwsRegion:   "region",
+						AwsAccount:  "account",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+							},
 						},
 					},
 				},
@@ -794,7 +1189,7 @@ func TestConfigurations_GetK8sObjectForCluster(t *testing.T) {
 				ContainerName:  "",
 				Namespace:      "",
 			},
-			expectedErr: errors.New("please pass any of the components between aws-node, coredns, cluster-autoscaler, kube-proxy"),
+			expectedErr: errors.New("please pass any of the components between aws-node, cluster-autoscaler, coredns, kube-proxy"),
 		},
 		{
 			name: "when the cluster name is not present",
@@ -804,21 +1199,23 @@ func TestConfigurations_GetK8sObjectForCluster(t *testing.T) {
 						ClusterName: "cluster2",
 						AwsRegion:   "region",
 						AwsAccount:  "account",
-						AwsNodeObject: K8sObject{
-							DeploymentName: "aws-node",
-							ObjectType:     "daemonset",
-						},
-						ClusterAutoscalerObject: K8sObject{
-							DeploymentName: "cluster-autoscaler",
-							ObjectType:     "deployment",
-						},
-						KubeProxyObject: K8sObject{
-							DeploymentName: "kube-proxy",
-							ObjectType:     "daemonset",
-						},
-						CoreDnsObject: K8sObject{
-							DeploymentName: "coredns",
-							ObjectType:     "deployment",
+						Components: map[string]K8sObject{
+							"aws-node": {
+								DeploymentName: "aws-node",
+								ObjectType:     "daemonset",
+							},
+							"cluster-autoscaler": {
+								DeploymentName: "cluster-autoscaler",
+								ObjectType:     "deployment",
+							},
+							"kube-proxy": {
+								DeploymentName: "kube-proxy",
+								ObjectType:     "daemonset",
+							},
+							"coredns": {
+								DeploymentName: "coredns",
+								ObjectType:     "deployment",
+							},
 						},
 					},
 				},
@@ -865,33 +1262,69 @@ func TestRead(t *testing.T) {
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  aws-node: \"aws-node-version\"\n  cluster-autoscaler: \"cluster-autoscaler-version\"\n  coredns: \"core-dns-version\"\n  kube-proxy: \"kube-proxy-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  AwsNodeObject:\n    ObjectType: \"daemonset\"\n    DeploymentName: \"aws-node\"\n    ContainerName: \"container-name\"\n    Namespace: \"kube-system\"\n  ClusterAutoscalerObject:\n    ObjectType: \"deployment\"\n    DeploymentName: \"cluster-autoscaler\"\n    ContainerName: \"container-name\"\n    Namespace: \"kube-system\"\n  CoreDnsObject:\n    ObjectType: \"deployment\"\n    DeploymentName: \"coredns\"\n    ContainerName: \"container-name\"\n    Namespace: \"kube-system\"\n  KubeProxyObject:\n    ObjectType: \"daemonset\"\n    DeploymentName: \"kube-proxy\"\n    ContainerName: \"container-name\"\n    Namespace: \"kube-system\"\n- ClusterName: \"cluster2\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  AwsNodeObject:\n    ObjectType: \"daemonset\"\n    DeploymentName: \"aws-node\"\n    ContainerName: \"container-name\"\n    Namespace: \"kube-system\"\n  ClusterAutoscalerObject:\n    ObjectType: \"deployment\"\n    DeploymentName: \"cluster-autoscaler\"\n    ContainerName: \"container-name\"\n    Namespace: \"kube-system\"\n  CoreDnsObject:\n    ObjectType: \"deployment\"\n    DeploymentName: \"coredns\"\n    ContainerName: \"container-name\"\n    Namespace: \"kube-system\"\n  KubeProxyObject:\n    ObjectType: \"daemonset\"\n    DeploymentName: \"kube-proxy\"\n    ContainerName: \"container-name\"\n    Namespace: \"kube-system\"", writeFile: true},
 			nil,
 		},
+		{"when the config file is present with components configured with the Components key and read successfully",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  coredns: \"core-dns-version\"\n  metrics-server: \"metrics-server-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n    metrics-server:\n      ObjectType: \"deployment\"\n      DeploymentName: \"metrics-server\"\n      ContainerName: \"metrics-server\"\n      Namespace: \"kube-system\"\n", writeFile: true},
+			nil,
+		},
+		{"when the config file is present with component versions overridden for a cluster and read successfully",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  coredns: \"core-dns-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  ComponentVersions:\n    coredns: \"core-dns-cluster-version\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n", writeFile: true},
+			nil,
+		},
+		{"when the config file is present and read successfully, but a component version overridden for a cluster is empty",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  coredns: \"core-dns-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  ComponentVersions:\n    coredns: \"\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n", writeFile: true},
+			ValidationErrors{{"clusterlist[0].ComponentVersions.coredns", "empty"}},
+		},
+		{"when the config file is present with a componentmatrix and read successfully",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponentmatrix:\n  \"1.26\":\n    coredns: \"v1.9.3-eksbuild.2\"\n  \"1.27\":\n    coredns: \"v1.10.1-eksbuild.2\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n", writeFile: true},
+			nil,
+		},
+		{"when the config file is present with a componentmatrix and read successfully, but a cluster has no object for a component of one of the rows",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponentmatrix:\n  \"1.26\":\n    coredns: \"v1.9.3-eksbuild.2\"\n  \"1.27\":\n    kube-proxy: \"v1.27.1-eksbuild.1\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n", writeFile: true},
+			ValidationErrors{missingComponentObject("clusterlist[0].Components.kube-proxy")},
+		},
+		{"when the config file is present with a componentmatrix, but a row key is not quoted",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponentmatrix:\n  1.30:\n    coredns: \"v1.11.1-eksbuild.4\"\n  \"1.29\":\n    coredns: \"v1.11.1-eksbuild.4\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n", writeFile: true},
+			ValidationErrors{{`componentmatrix["1.30"]`, `not a string, YAML reads 1.30 as a number, please quote the kubernetes version like "1.30"`}},
+		},
+		{"when the config file is present and read successfully, but one of the component versions is empty",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  coredns: \"\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n", writeFile: true},
+			ValidationErrors{{"components.coredns", "empty"}, missingComponentObject("clusterlist[0].Components.coredns")},
+		},
 		{"when the config file is present and read successfully, but one of the keys for cluster list config is not present with the value",
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  aws-node: \"aws-node-version\"\n  cluster-autoscaler: \"cluster-autoscaler-version\"\n  coredns: \"core-dns-version\"\n  kube-proxy: \"kube-proxy-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n- ClusterName: \"cluster2\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"\"\n", writeFile: true},
-			errors.New("one of the clusterlist elements has either ClusterName, AwsRegion, AwsAccount, AwsNodeObject, ClusterAutoscalerObject, KubeProxyObject, CoreDnsObject is missing"),
+			ValidationErrors{missingComponentObject("clusterlist[0].Components.aws-node"), missingComponentObject("clusterlist[0].Components.cluster-autoscaler"), missingComponentObject("clusterlist[0].Components.coredns"), missingComponentObject("clusterlist[0].Components.kube-proxy"), {"clusterlist[1].AwsAccount", "empty"}, missingComponentObject("clusterlist[1].Components.aws-node"), missingComponentObject("clusterlist[1].Components.cluster-autoscaler"), missingComponentObject("clusterlist[1].Components.coredns"), missingComponentObject("clusterlist[1].Components.kube-proxy")},
 		},
 		{"when the config file is present and read successfully, but one of the keys for cluster list config is not present with the key itself",
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  aws-node: \"aws-node-version\"\n  cluster-autoscaler: \"cluster-autoscaler-version\"\n  coredns: \"core-dns-version\"\n  kube-proxy: \"kube-proxy-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n- ClusterName: \"cluster2\"\n  AwsRegion: \"region1\"\n", writeFile: true},
-			errors.New("one of the clusterlist elements has either ClusterName, AwsRegion, AwsAccount, AwsNodeObject, ClusterAutoscalerObject, KubeProxyObject, CoreDnsObject is missing"),
+			ValidationErrors{missingComponentObject("clusterlist[0].Components.aws-node"), missingComponentObject("clusterlist[0].Components.cluster-autoscaler"), missingComponentObject("clusterlist[0].Components.coredns"), missingComponentObject("clusterlist[0].Components.kube-proxy"), {"clusterlist[1].AwsAccount", "empty"}, missingComponentObject("clusterlist[1].Components.aws-node"), missingComponentObject("clusterlist[1].Components.cluster-autoscaler"), missingComponentObject("clusterlist[1].Components.coredns"), missingComponentObject("clusterlist[1].Components.kube-proxy")},
 		},
 		{"when the config file is present and read successfully, but kube-proxy config is not present",
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  aws-node: \"aws-node-version\"\n  cluster-autoscaler: \"cluster-autoscaler-version\"\n  coredns: \"core-dns-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n- ClusterName: \"cluster2\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n", writeFile: true},
-			errors.New("mandatory component version of either aws-node, coredns, kube-proxy or cluster-autoscaler not set in config file"),
+			ValidationErrors{missingComponentObject("clusterlist[0].Components.aws-node"), missingComponentObject("clusterlist[0].Components.cluster-autoscaler"), missingComponentObject("clusterlist[0].Components.coredns"), missingComponentObject("clusterlist[1].Components.aws-node"), missingComponentObject("clusterlist[1].Components.cluster-autoscaler"), missingComponentObject("clusterlist[1].Components.coredns")},
 		},
 		{"when the config file is present and read successfully, but aws-node config is not present",
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  kube-proxy: \"kube-proxy-version\"\n  cluster-autoscaler: \"cluster-autoscaler-version\"\n  coredns: \"core-dns-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n- ClusterName: \"cluster2\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n", writeFile: true},
-			errors.New("mandatory component version of either aws-node, coredns, kube-proxy or cluster-autoscaler not set in config file"),
+			ValidationErrors{missingComponentObject("clusterlist[0].Components.cluster-autoscaler"), missingComponentObject("clusterlist[0].Components.coredns"), missingComponentObject("clusterlist[0].Components.kube-proxy"), missingComponentObject("clusterlist[1].Components.cluster-autoscaler"), missingComponentObject("clusterlist[1].Components.coredns"), missingComponentObject("clusterlist[1].Components.kube-proxy")},
 		},
 		{"when the config file is present and read successfully, but cluster-autoscaler config is not present",
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  kube-proxy: \"kube-proxy-version\"\n  aws-node: \"aws-node-version\"\n  coredns: \"core-dns-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n- ClusterName: \"cluster2\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n", writeFile: true},
-			errors.New("mandatory component version of either aws-node, coredns, kube-proxy or cluster-autoscaler not set in config file"),
+			ValidationErrors{missingComponentObject("clusterlist[0].Components.aws-node"), missingComponentObject("clusterlist[0].Components.coredns"), missingComponentObject("clusterlist[0].Components.kube-proxy"), missingComponentObject("clusterlist[1].Components.aws-node"), missingComponentObject("clusterlist[1].Components.coredns"), missingComponentObject("clusterlist[1].Components.kube-proxy")},
 		},
 		{"when the config file is present and read successfully, but coredns config is not present",
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  kube-proxy: \"kube-proxy-version\"\n  aws-node: \"aws-node-version\"\n  cluster-autoscaler: \"cluster-autoscaler-version\"\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n- ClusterName: \"cluster2\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n", writeFile: true},
-			errors.New("mandatory component version of either aws-node, coredns, kube-proxy or cluster-autoscaler not set in config file"),
+			ValidationErrors{missingComponentObject("clusterlist[0].Components.aws-node"), missingComponentObject("clusterlist[0].Components.cluster-autoscaler"), missingComponentObject("clusterlist[0].Components.kube-proxy"), missingComponentObject("clusterlist[1].Components.aws-node"), missingComponentObject("clusterlist[1].Components.cluster-autoscaler"), missingComponentObject("clusterlist[1].Components.kube-proxy")},
+		},
+		{"when the config file is present in the schema of v0.1.0 and read successfully",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\ncomponents:\n  coredns: \"core-dns-version\"\nclusterlist:\n- Name: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  CoreDnsObject:\n    type: \"deployment\"\n    name: \"coredns\"", writeFile: true},
+			nil,
+		},
+		{"when the config file is present, but its version is newer than the version supported",
+			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "---\nversion: 4\ncomponents:\n  coredns: \"core-dns-version\"\n", writeFile: true},
+			errors.New("the config file version 4 is newer than the version 3 supported, please update k8s-cluster-upgrade-tool"),
 		},
 		{"when the config file is not present",
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "", writeFile: false},
-			errors.New("error finding config file. Does it exist? Please create it in $HOME/.k8s-cluster-upgrade-tool/config.yaml if not"),
+			errors.New("error finding config file. Does it exist? Please create it in /tmp/config.yaml if not"),
 		},
 		{"when the config file is present, but reading fails as data type inside is not yaml",
 			File{fileName: "config", fileType: "yaml", dirName: "/tmp", data: "foo baz", writeFile: true},
@@ -902,7 +1335,7 @@ func TestRead(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.file.writeFile {
-				fileContent := []byte(fmt.Sprintf(tt.file.data))
+				fileContent := []byte(tt.file.data)
 				err := ioutil.WriteFile(fmt.Sprintf("%s/%s.%s", tt.file.dirName, tt.file.fileName, tt.file.fileType),
 					fileContent, 0644)
 				if err != nil {
@@ -918,6 +1351,10 @@ func TestRead(t *testing.T) {
 	}
 }
 
+func missingComponentObject(path string) ValidationError {
+	return ValidationError{path, "missing, the component has a version set but no object configured for the cluster"}
+}
+
 func TestFileMetadata(t *testing.T) {
 	t.Run("returns the correct path, filetype and directory", func(t *testing.T) {
 		gotFileName, gotFileType, gotFilePath := FileMetadata()
@@ -927,3 +1364,317 @@ func TestFileMetadata(t *testing.T) {
 		assert.Equal(t, gotFilePath, "$HOME/.k8s-cluster-upgrade-tool")
 	})
 }
+
+func TestFileMetadataForPath(t *testing.T) {
+	tests := []struct {
+		name, path                           string
+		wantFileName, wantFileType, wantPath string
+	}{
+		{"returns the metadata of a relative path", "configs/config.yaml", "config", "yaml", "configs"},
+		{"returns the metadata of an absolute path", "/tmp/prod.yml", "prod", "yml", "/tmp"},
+		{"returns the metadata of a file in the current directory", "config.yaml", "config", "yaml", "."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFileName, gotFileType, gotFilePath := FileMetadataForPath(tt.path)
+
+			assert.Equal(t, tt.wantFileName, gotFileName)
+			assert.Equal(t, tt.wantFileType, gotFileType)
+			assert.Equal(t, tt.wantPath, gotFilePath)
+		})
+	}
+}
+
+func TestLocate(t *testing.T) {
+	workingDirectory, err := os.Getwd()
+	assert.Nil(t, err)
+	home := t.TempDir()
+	xdgConfigHome := t.TempDir()
+	currentDirectory := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdgConfigHome)
+	assert.Nil(t, os.Chdir(currentDirectory))
+	defer os.Chdir(workingDirectory)
+
+	writeConfigFile := func(directory string) {
+		assert.Nil(t, os.MkdirAll(directory, 0755))
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(directory, "config.yaml"), []byte("components: {}\n"), 0644))
+	}
+
+	t.Run("when no config file is found it returns an error listing the search paths", func(t *testing.T) {
+		_, err := Locate("")
+
+		assert.EqualError(t, err, fmt.Sprintf("error finding config file. Does it exist? Please create it in one of "+
+			".k8s-cluster-upgrade-tool/config.yaml, %s/k8s-cluster-upgrade-tool/config.yaml, %s/.k8s-cluster-upgrade-tool/config.yaml, "+
+			"or pass its path with --config or K8S_CLUSTER_UPGRADE_TOOL_CONFIG", xdgConfigHome, home))
+	})
+
+	t.Run("returns the config file of the home directory", func(t *testing.T) {
+		writeConfigFile(filepath.Join(home, ".k8s-cluster-upgrade-tool"))
+
+		got, err := Locate("")
+
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(home, ".k8s-cluster-upgrade-tool", "config.yaml"), got)
+	})
+
+	t.Run("returns the config file of the XDG config directory before the one of the home directory", func(t *testing.T) {
+		writeConfigFile(filepath.Join(xdgConfigHome, "k8s-cluster-upgrade-tool"))
+
+		got, err := Locate("")
+
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(xdgConfigHome, "k8s-cluster-upgrade-tool", "config.yaml"), got)
+	})
+
+	t.Run("returns the config file of the current directory before the ones of the other search paths", func(t *testing.T) {
+		writeConfigFile(".k8s-cluster-upgrade-tool")
+
+		got, err := Locate("")
+
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(".k8s-cluster-upgrade-tool", "config.yaml"), got)
+	})
+
+	t.Run("returns the path of the environment variable before the search paths", func(t *testing.T) {
+		t.Setenv("K8S_CLUSTER_UPGRADE_TOOL_CONFIG", "/etc/k8s-cluster-upgrade-tool/team-a.yaml")
+
+		got, err := Locate("")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "/etc/k8s-cluster-upgrade-tool/team-a.yaml", got)
+	})
+
+	t.Run("returns the path passed before the environment variable", func(t *testing.T) {
+		t.Setenv("K8S_CLUSTER_UPGRADE_TOOL_CONFIG", "/etc/k8s-cluster-upgrade-tool/team-a.yaml")
+
+		got, err := Locate("team-b.yaml")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "team-b.yaml", got)
+	})
+}
+
+func TestLoader_Locate(t *testing.T) {
+	home := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(home, ".k8s-cluster-upgrade-tool"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(home, ".k8s-cluster-upgrade-tool", "config.yaml"), []byte("components: {}\n"), 0644))
+	environment := func(variables map[string]string) func(string) string {
+		return func(key string) string { return variables[key] }
+	}
+
+	t.Run("returns the config file of the home directory of the environment of the Loader", func(t *testing.T) {
+		t.Parallel()
+		loader := Loader{Getenv: environment(map[string]string{"HOME": home, "XDG_CONFIG_HOME": t.TempDir()})}
+
+		got, err := loader.Locate("")
+
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(home, ".k8s-cluster-upgrade-tool", "config.yaml"), got)
+	})
+
+	t.Run("returns the path of the environment variable of the environment of the Loader", func(t *testing.T) {
+		t.Parallel()
+		loader := Loader{Getenv: environment(map[string]string{"K8S_CLUSTER_UPGRADE_TOOL_CONFIG": "/etc/team-a.yaml"})}
+
+		got, err := loader.Locate("")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "/etc/team-a.yaml", got)
+	})
+}
+
+func TestLoader_Load(t *testing.T) {
+	configYaml := func(version string) string {
+		return fmt.Sprintf("components:\n  coredns: %q\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n", version)
+	}
+	dirA, dirB := t.TempDir(), t.TempDir()
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dirA, "config.yaml"), []byte(configYaml("v1.8.4")), 0644))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dirB, "config.yaml"), []byte(configYaml("v1.9.3")), 0644))
+
+	t.Run("when two config files are loaded they are read independently of each other", func(t *testing.T) {
+		loader := Loader{}
+
+		gotA, errA := loader.Load(filepath.Join(dirA, "config.yaml"))
+		gotB, errB := loader.Load(filepath.Join(dirB, "config.yaml"))
+
+		assert.Nil(t, errA)
+		assert.Nil(t, errB)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.4"}, gotA.Components)
+		assert.Equal(t, filepath.Join(dirA, "config.yaml"), gotA.File)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.9.3"}, gotB.Components)
+		assert.Equal(t, filepath.Join(dirB, "config.yaml"), gotB.File)
+	})
+
+	t.Run("when an environment variable overrides a component version the overridden version is validated", func(t *testing.T) {
+		loader := Loader{Getenv: func(key string) string {
+			return map[string]string{"K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_COREDNS": "~latest"}[key]
+		}}
+
+		_, err := loader.Load(filepath.Join(dirA, "config.yaml"))
+
+		assert.Equal(t, ValidationErrors{{"components.coredns", "invalid constraint ~latest, invalid version latest"}}, err)
+	})
+
+	t.Run("when the config file is loaded with a relative path the File is absolute", func(t *testing.T) {
+		workingDirectory, err := os.Getwd()
+		assert.Nil(t, err)
+		assert.Nil(t, os.Chdir(dirA))
+		defer os.Chdir(workingDirectory)
+
+		got, err := Loader{}.Load("config.yaml")
+
+		assert.Nil(t, err)
+		assert.True(t, filepath.IsAbs(got.File))
+		assert.Equal(t, "config.yaml", filepath.Base(got.File))
+	})
+}
+
+func TestReadWithClustersDirectory(t *testing.T) {
+	clusterYaml := func(clusterName string) string {
+		return fmt.Sprintf("- ClusterName: %q\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n", clusterName)
+	}
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, "clusters.d"), 0755))
+		for name, data := range files {
+			assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+		}
+		return dir
+	}
+
+	t.Run("when the clusters directory has files the clusters of every file are read after the ones of the config file", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"config.yaml":             "components:\n  coredns: \"core-dns-version\"\nclusterlist:\n" + clusterYaml("cluster1"),
+			"clusters.d/b-prod.yml":   "clusterlist:\n" + clusterYaml("prod-1") + clusterYaml("prod-2"),
+			"clusters.d/a-stage.yaml": "clusterlist:\n" + clusterYaml("stage-1"),
+			"clusters.d/empty.yaml":   "",
+			"clusters.d/README.md":    "clusters of every environment",
+		})
+
+		got, err := Read("config", "yaml", dir)
+
+		assert.Nil(t, err)
+		var gotClusters []string
+		var gotSources []ClusterSource
+		for _, cluster := range got.ClusterList {
+			gotClusters = append(gotClusters, cluster.ClusterName)
+			gotSources = append(gotSources, cluster.Source)
+		}
+		assert.Equal(t, []string{"cluster1", "stage-1", "prod-1", "prod-2"}, gotClusters)
+		assert.Equal(t, []ClusterSource{
+			{File: filepath.Join(dir, "config.yaml"), Index: 0},
+			{File: filepath.Join(dir, "clusters.d", "a-stage.yaml"), Index: 0},
+			{File: filepath.Join(dir, "clusters.d", "b-prod.yml"), Index: 0},
+			{File: filepath.Join(dir, "clusters.d", "b-prod.yml"), Index: 1},
+		}, gotSources)
+	})
+
+	t.Run("when a cluster is read from the clusters directory it is read like from the config file", func(t *testing.T) {
+		cluster := "  AwsRegion: region1\n  AwsAccount: 123456789012\n  Labels:\n    Env: Prod\n  ComponentVersions:\n" +
+			"    CoreDNS: v1.10.1\n  Components:\n    CoreDNS:\n      ObjectType: deployment\n      DeploymentName: coredns\n" +
+			"      ContainerName: coredns\n      Namespace: kube-system\n"
+		dir := writeFiles(t, map[string]string{
+			"config.yaml":          "components:\n  coredns: \"core-dns-version\"\nclusterlist:\n" + clusterYaml("cluster1") + "- ClusterName: prod-1\n" + cluster,
+			"clusters.d/prod.yaml": "ClusterList:\n- ClusterName: prod-2\n" + cluster,
+		})
+
+		got, err := Read("config", "yaml", dir)
+
+		assert.Nil(t, err)
+		fromConfigFile, fromClustersDir := got.ClusterList[1], got.ClusterList[2]
+		assert.Equal(t, "123456789012", fromClustersDir.AwsAccount)
+		fromConfigFile.ClusterName, fromConfigFile.Source, fromClustersDir.ClusterName, fromClustersDir.Source = "", ClusterSource{}, "", ClusterSource{}
+		assert.Equal(t, fromConfigFile, fromClustersDir)
+	})
+
+	t.Run("when a cluster name is duplicated across files it returns the files of the duplicates", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"config.yaml":           "components:\n  coredns: \"core-dns-version\"\nclusterlist:\n" + clusterYaml("cluster1") + clusterYaml("prod-1"),
+			"clusters.d/prod.yaml":  "clusterlist:\n" + clusterYaml("prod-2") + clusterYaml("prod-1"),
+			"clusters.d/stage.yaml": "clusterlist:\n- ClusterName: \"prod-1\"\n",
+		})
+
+		_, err := Read("config", "yaml", dir)
+
+		assert.Equal(t, ValidationErrors{
+			{"clusterlist", `duplicate ClusterName "prod-1" at [1], clusters.d/prod.yaml:clusterlist[1] and clusters.d/stage.yaml:clusterlist[0]`},
+			{"clusters.d/stage.yaml:clusterlist[0].AwsRegion", "empty"},
+			{"clusters.d/stage.yaml:clusterlist[0].AwsAccount", "empty"},
+			missingComponentObject("clusters.d/stage.yaml:clusterlist[0].Components.coredns"),
+		}, err)
+	})
+
+	t.Run("when a file of the clusters directory is not yaml it returns an error", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"config.yaml":          "components:\n  coredns: \"core-dns-version\"\n",
+			"clusters.d/prod.yaml": "- foo",
+		})
+
+		_, err := Read("config", "yaml", dir)
+
+		assert.EqualError(t, err, filepath.Join(dir, "clusters.d", "prod.yaml")+": error reading from config file")
+	})
+}
+
+func TestClustersFiles(t *testing.T) {
+	t.Run("when the clusters directory has files it returns the yaml files in the order of their names", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, "clusters.d"), 0755))
+		for _, name := range []string{"b-prod.yml", "a-stage.yaml", "README.md", "a-stage.yaml.bak"} {
+			assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "clusters.d", name), []byte(""), 0644))
+		}
+
+		got, err := ClustersFiles(filepath.Join(dir, "config.yaml"))
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{filepath.Join(dir, "clusters.d", "a-stage.yaml"), filepath.Join(dir, "clusters.d", "b-prod.yml")}, got)
+	})
+
+	t.Run("when there is no clusters directory it returns no files", func(t *testing.T) {
+		got, err := ClustersFiles(filepath.Join(t.TempDir(), "config.yaml"))
+
+		assert.Nil(t, err)
+		assert.Empty(t, got)
+	})
+}
+
+func TestConfigurations_SelectClusters(t *testing.T) {
+	configuration := Configurations{
+		ClusterList: []ClusterListConfiguration{
+			{ClusterName: "staging-1", Labels: map[string]string{"env": "staging", "team": "payments"}},
+			{ClusterName: "production-1", Labels: map[string]string{"env": "production", "team": "payments"}},
+			{ClusterName: "staging-2", Labels: map[string]string{"env": "staging", "team": "search"}},
+			{ClusterName: "sandbox"},
+		},
+		Groups: map[string]GroupConfiguration{
+			"payments":  {Selector: "team=payments"},
+			"canary":    {Clusters: []string{"sandbox", "staging-2"}},
+			"first-run": {Selector: "env=staging", Clusters: []string{"sandbox"}},
+		},
+	}
+	tests := []struct {
+		name     string
+		selector string
+		group    string
+		want     []string
+		err      error
+	}{
+		{"when a selector is passed it returns the clusters matching it", "env=staging", "", []string{"staging-1", "staging-2"}, nil},
+		{"when a group with a selector is passed it returns the clusters matching its selector", "", "payments", []string{"staging-1", "production-1"}, nil},
+		{"when a group with clusters is passed it returns its clusters in the order of the clusterlist", "", "canary", []string{"staging-2", "sandbox"}, nil},
+		{"when a group with a selector and clusters is passed it returns both", "", "first-run", []string{"staging-1", "staging-2", "sandbox"}, nil},
+		{"when a group name is passed in another case it returns the clusters of the group", "", "Canary", []string{"staging-2", "sandbox"}, nil},
+		{"when both a selector and a group are passed it returns the clusters of the group matching the selector", "env=staging", "payments", []string{"staging-1"}, nil},
+		{"when no cluster matches it returns an error", "env=development", "", nil, errors.New("no cluster matches the selector and group passed")},
+		{"when the group doesn't exist it returns an error", "", "platform", nil, errors.New("please pass a valid group name from this list [canary, first-run, payments]")},
+		{"when the selector is invalid it returns an error", "=staging", "", nil, errors.New("invalid selector =staging")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := configuration.SelectClusters(tt.selector, tt.group)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
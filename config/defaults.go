@@ -0,0 +1,67 @@
+package config
+
+// applyDefaults deep-merges the Defaults into every element of the clusterlist, the values set for a cluster take
+// precedence over the defaults, down to the single keys of the objects under Components
+func (c *Configurations) applyDefaults() {
+	for index := range c.ClusterList {
+		c.ClusterList[index] = c.Defaults.mergedInto(c.ClusterList[index])
+	}
+}
+
+// mergedInto returns a copy of the cluster with the values of the defaults set for every key the cluster has no value for
+func (d ClusterListConfiguration) mergedInto(cluster ClusterListConfiguration) ClusterListConfiguration {
+	if cluster.AwsRegion == "" {
+		cluster.AwsRegion = d.AwsRegion
+	}
+	if cluster.AwsAccount == "" {
+		cluster.AwsAccount = d.AwsAccount
+	}
+	if cluster.KubeconfigPath == "" {
+		cluster.KubeconfigPath = d.KubeconfigPath
+	}
+
+	if len(d.Components) != 0 {
+		components := make(map[string]K8sObject, len(d.Components))
+		for componentName, k8sObject := range d.Components {
+			components[componentName] = k8sObject
+		}
+		for componentName, k8sObject := range cluster.Components {
+			components[componentName] = d.Components[componentName].mergedInto(k8sObject)
+		}
+		cluster.Components = components
+	}
+
+	if len(d.ComponentVersions) != 0 {
+		cluster.ComponentVersions = d.ComponentVersions.MergedWith(cluster.ComponentVersions)
+	}
+
+	if len(d.Labels) != 0 {
+		labels := make(map[string]string, len(d.Labels))
+		for key, value := range d.Labels {
+			labels[key] = value
+		}
+		for key, value := range cluster.Labels {
+			labels[key] = value
+		}
+		cluster.Labels = labels
+	}
+	return cluster
+}
+
+// mergedInto returns a copy of the object with the values of the default object set for every key the object has no
+// value for
+func (d K8sObject) mergedInto(k8sObject K8sObject) K8sObject {
+	if k8sObject.DeploymentName == "" {
+		k8sObject.DeploymentName = d.DeploymentName
+	}
+	if k8sObject.ObjectType == "" {
+		k8sObject.ObjectType = d.ObjectType
+	}
+	if k8sObject.ContainerName == "" {
+		k8sObject.ContainerName = d.ContainerName
+	}
+	if k8sObject.Namespace == "" {
+		k8sObject.Namespace = d.Namespace
+	}
+	return k8sObject
+}
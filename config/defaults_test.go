@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurations_applyDefaults(t *testing.T) {
+	defaults := ClusterListConfiguration{
+		AwsRegion:      "eu-west-1",
+		AwsAccount:     "account1",
+		KubeconfigPath: "~/.kube/config",
+		Components: map[string]K8sObject{
+			"coredns":  {DeploymentName: "coredns", ObjectType: "deployment", ContainerName: "coredns", Namespace: "kube-system"},
+			"aws-node": {DeploymentName: "aws-node", ObjectType: "daemonset", ContainerName: "aws-node", Namespace: "kube-system"},
+		},
+		Labels: map[string]string{"team": "platform"},
+	}
+
+	t.Run("when a cluster only sets its name every default is merged into it", func(t *testing.T) {
+		configuration := Configurations{Defaults: defaults, ClusterList: []ClusterListConfiguration{{ClusterName: "cluster1"}}}
+
+		configuration.applyDefaults()
+
+		assert.Equal(t, ClusterListConfiguration{
+			ClusterName:    "cluster1",
+			AwsRegion:      "eu-west-1",
+			AwsAccount:     "account1",
+			KubeconfigPath: "~/.kube/config",
+			Components:     defaults.Components,
+			Labels:         map[string]string{"team": "platform"},
+		}, configuration.ClusterList[0])
+	})
+
+	t.Run("when a cluster sets values they take precedence over the defaults down to the keys of the objects", func(t *testing.T) {
+		configuration := Configurations{Defaults: defaults, ClusterList: []ClusterListConfiguration{{
+			ClusterName:    "cluster1",
+			AwsRegion:      "us-east-1",
+			KubeconfigPath: "~/.kube/cluster1",
+			Components: map[string]K8sObject{
+				"coredns":        {Namespace: "dns"},
+				"metrics-server": {DeploymentName: "metrics-server", ObjectType: "deployment", ContainerName: "metrics-server", Namespace: "kube-system"},
+			},
+			Labels: map[string]string{"env": "staging", "team": "payments"},
+		}}}
+
+		configuration.applyDefaults()
+
+		assert.Equal(t, ClusterListConfiguration{
+			ClusterName:    "cluster1",
+			AwsRegion:      "us-east-1",
+			AwsAccount:     "account1",
+			KubeconfigPath: "~/.kube/cluster1",
+			Components: map[string]K8sObject{
+				"aws-node":       {DeploymentName: "aws-node", ObjectType: "daemonset", ContainerName: "aws-node", Namespace: "kube-system"},
+				"coredns":        {DeploymentName: "coredns", ObjectType: "deployment", ContainerName: "coredns", Namespace: "dns"},
+				"metrics-server": {DeploymentName: "metrics-server", ObjectType: "deployment", ContainerName: "metrics-server", Namespace: "kube-system"},
+			},
+			Labels: map[string]string{"env": "staging", "team": "payments"},
+		}, configuration.ClusterList[0])
+	})
+
+	t.Run("when no defaults are set the clusters are unchanged", func(t *testing.T) {
+		cluster := validCluster("cluster1")
+		configuration := Configurations{ClusterList: []ClusterListConfiguration{cluster}}
+
+		configuration.applyDefaults()
+
+		assert.Equal(t, cluster, configuration.ClusterList[0])
+	})
+}
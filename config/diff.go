@@ -0,0 +1,127 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// ComponentVersionChange is a change of the version a component is expected to run with between two config files, for
+// the clusters it applies to. From or To are empty when no version is configured for the component in the config file
+type ComponentVersionChange struct {
+	ComponentName string
+	// KubernetesMinorVersion is the componentmatrix row the change applies to, it is empty when neither of the config
+	// files has a componentmatrix
+	KubernetesMinorVersion string
+	From                   string
+	To                     string
+	ClusterNames           []string
+}
+
+// ConfigDiff is the difference between two config files in the clusters and the versions the components of the
+// clusters are expected to run with
+type ConfigDiff struct {
+	AddedClusters   []string
+	RemovedClusters []string
+	Changes         []ComponentVersionChange
+}
+
+// Diff returns the difference between the from and to config files. The component versions are compared for every
+// cluster present in both, for every kubernetes minor version of the componentmatrix rows of either config file when
+// they have a componentmatrix, and the changes of the clusters are grouped when they are the same
+func Diff(from, to Configurations) ConfigDiff {
+	var diff ConfigDiff
+	for _, cluster := range to.ClusterList {
+		if !from.IsClusterNameValid(cluster.ClusterName) {
+			diff.AddedClusters = append(diff.AddedClusters, cluster.ClusterName)
+		}
+	}
+	for _, cluster := range from.ClusterList {
+		if !to.IsClusterNameValid(cluster.ClusterName) {
+			diff.RemovedClusters = append(diff.RemovedClusters, cluster.ClusterName)
+		}
+	}
+
+	minorVersions := componentMatrixMinorVersions(from, to)
+	changesByKey := map[string]int{}
+	for _, cluster := range to.ClusterList {
+		if !from.IsClusterNameValid(cluster.ClusterName) {
+			continue
+		}
+		for _, minorVersion := range minorVersions {
+			for _, change := range DiffClusterVersions(from, to, cluster.ClusterName, minorVersion) {
+				key := strings.Join([]string{change.ComponentName, change.KubernetesMinorVersion, change.From, change.To}, "\x00")
+				index, present := changesByKey[key]
+				if !present {
+					index = len(diff.Changes)
+					changesByKey[key] = index
+					diff.Changes = append(diff.Changes, change)
+					continue
+				}
+				diff.Changes[index].ClusterNames = append(diff.Changes[index].ClusterNames, cluster.ClusterName)
+			}
+		}
+	}
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		if diff.Changes[i].ComponentName != diff.Changes[j].ComponentName {
+			return diff.Changes[i].ComponentName < diff.Changes[j].ComponentName
+		}
+		return diff.Changes[i].KubernetesMinorVersion < diff.Changes[j].KubernetesMinorVersion
+	})
+	return diff
+}
+
+// DiffClusterVersions returns the changes of the versions the components of the cluster are expected to run with
+// between the from and to config files, for a cluster running on the passed kubernetes minor version
+func DiffClusterVersions(from, to Configurations, clusterName, kubernetesMinorVersion string) []ComponentVersionChange {
+	fromVersions := componentVersionsOrEmpty(from, clusterName, kubernetesMinorVersion)
+	toVersions := componentVersionsOrEmpty(to, clusterName, kubernetesMinorVersion)
+	if !from.HasComponentMatrix() && !to.HasComponentMatrix() {
+		kubernetesMinorVersion = ""
+	}
+
+	var changes []ComponentVersionChange
+	for _, componentName := range fromVersions.MergedWith(toVersions).Names() {
+		if fromVersions[componentName] == toVersions[componentName] {
+			continue
+		}
+		changes = append(changes, ComponentVersionChange{
+			ComponentName:          componentName,
+			KubernetesMinorVersion: kubernetesMinorVersion,
+			From:                   fromVersions[componentName],
+			To:                     toVersions[componentName],
+			ClusterNames:           []string{clusterName},
+		})
+	}
+	return changes
+}
+
+// componentVersionsOrEmpty returns the component versions of the cluster, which are empty when the cluster isn't
+// present or the componentmatrix has no row for the kubernetes minor version
+func componentVersionsOrEmpty(c Configurations, clusterName, kubernetesMinorVersion string) ComponentVersionConfigurations {
+	componentVersions, err := c.GetComponentVersionsForCluster(clusterName, kubernetesMinorVersion)
+	if err != nil {
+		return ComponentVersionConfigurations{}
+	}
+	return componentVersions
+}
+
+// componentMatrixMinorVersions returns the kubernetes minor versions of the componentmatrix rows of the config files,
+// which is a single empty version when neither of them has a componentmatrix
+func componentMatrixMinorVersions(configurations ...Configurations) []string {
+	present := map[string]bool{}
+	var minorVersions []string
+	for _, configuration := range configurations {
+		for minorVersion := range configuration.ComponentMatrix {
+			if !present[minorVersion] {
+				present[minorVersion] = true
+				minorVersions = append(minorVersions, minorVersion)
+			}
+		}
+	}
+	if len(minorVersions) == 0 {
+		return []string{""}
+	}
+	sort.Strings(minorVersions)
+	return minorVersions
+}
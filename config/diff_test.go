@@ -0,0 +1,101 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	clusters := func(clusters ...ClusterListConfiguration) []ClusterListConfiguration { return clusters }
+	cluster := func(clusterName string, componentVersions ComponentVersionConfigurations) ClusterListConfiguration {
+		return ClusterListConfiguration{ClusterName: clusterName, ComponentVersions: componentVersions}
+	}
+
+	tests := []struct {
+		name string
+		from Configurations
+		to   Configurations
+		want ConfigDiff
+	}{
+		{
+			name: "when nothing changes the diff is empty",
+			from: Configurations{Components: ComponentVersionConfigurations{"coredns": "v1.8.4"}, ClusterList: clusters(cluster("cluster1", nil))},
+			to:   Configurations{Components: ComponentVersionConfigurations{"coredns": "v1.8.4"}, ClusterList: clusters(cluster("cluster1", nil))},
+			want: ConfigDiff{},
+		},
+		{
+			name: "when a component version changes the clusters not overriding it are affected",
+			from: Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4", "kube-proxy": "v1.21.2"},
+				ClusterList: clusters(cluster("cluster1", nil), cluster("cluster2", ComponentVersionConfigurations{"coredns": "v1.8.7"}), cluster("cluster3", nil)),
+			},
+			to: Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.9.3", "kube-proxy": "v1.21.2"},
+				ClusterList: clusters(cluster("cluster1", nil), cluster("cluster2", ComponentVersionConfigurations{"coredns": "v1.8.7"}), cluster("cluster3", nil)),
+			},
+			want: ConfigDiff{Changes: []ComponentVersionChange{
+				{ComponentName: "coredns", From: "v1.8.4", To: "v1.9.3", ClusterNames: []string{"cluster1", "cluster3"}},
+			}},
+		},
+		{
+			name: "when an override changes only its cluster is affected",
+			from: Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: clusters(cluster("cluster1", nil), cluster("cluster2", nil)),
+			},
+			to: Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: clusters(cluster("cluster1", nil), cluster("cluster2", ComponentVersionConfigurations{"coredns": "v1.9.3"})),
+			},
+			want: ConfigDiff{Changes: []ComponentVersionChange{
+				{ComponentName: "coredns", From: "v1.8.4", To: "v1.9.3", ClusterNames: []string{"cluster2"}},
+			}},
+		},
+		{
+			name: "when clusters are added and removed they are listed and their versions are not compared",
+			from: Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: clusters(cluster("cluster1", nil), cluster("cluster2", nil)),
+			},
+			to: Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: clusters(cluster("cluster1", nil), cluster("cluster3", ComponentVersionConfigurations{"coredns": "v1.9.3"})),
+			},
+			want: ConfigDiff{AddedClusters: []string{"cluster3"}, RemovedClusters: []string{"cluster2"}},
+		},
+		{
+			name: "when a component is added its version changes from not configured",
+			from: Configurations{Components: ComponentVersionConfigurations{"coredns": "v1.8.4"}, ClusterList: clusters(cluster("cluster1", nil))},
+			to: Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4", "kube-proxy": "v1.21.2"},
+				ClusterList: clusters(cluster("cluster1", nil)),
+			},
+			want: ConfigDiff{Changes: []ComponentVersionChange{
+				{ComponentName: "kube-proxy", To: "v1.21.2", ClusterNames: []string{"cluster1"}},
+			}},
+		},
+		{
+			name: "when the config files have a componentmatrix the changes are listed per row",
+			from: Configurations{
+				Components:      ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ComponentMatrix: map[string]ComponentVersionConfigurations{"1.26": {"coredns": "v1.9.3"}, "1.27": {"coredns": "v1.10.1"}},
+				ClusterList:     clusters(cluster("cluster1", nil)),
+			},
+			to: Configurations{
+				Components:      ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ComponentMatrix: map[string]ComponentVersionConfigurations{"1.26": {"coredns": "v1.9.3"}, "1.27": {"coredns": "v1.10.1-eksbuild.2"}, "1.28": {"coredns": "v1.10.1-eksbuild.4"}},
+				ClusterList:     clusters(cluster("cluster1", nil)),
+			},
+			want: ConfigDiff{Changes: []ComponentVersionChange{
+				{ComponentName: "coredns", KubernetesMinorVersion: "1.27", From: "v1.10.1", To: "v1.10.1-eksbuild.2", ClusterNames: []string{"cluster1"}},
+				{ComponentName: "coredns", KubernetesMinorVersion: "1.28", To: "v1.10.1-eksbuild.4", ClusterNames: []string{"cluster1"}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Diff(tt.from, tt.to))
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EnvironmentVariablePrefix is the prefix of the environment variables overriding values of the config file. The names
+// of components, clusters and kubernetes versions are upper cased in the environment variable names, with every
+// character other than letters and digits replaced by an underscore
+//   - K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_<COMPONENT> overrides the version of a component under components
+//   - K8S_CLUSTER_UPGRADE_TOOL_COMPONENTMATRIX_<KUBERNETES_VERSION>_<COMPONENT> overrides the version of a component in
+//     a row of the componentmatrix
+//   - K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_<CLUSTER>_AWS_REGION and K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_<CLUSTER>_AWS_ACCOUNT
+//     override the AwsRegion and AwsAccount of a cluster
+//   - K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_<CLUSTER>_COMPONENT_VERSIONS_<COMPONENT> overrides the version of a component
+//     for a cluster
+const EnvironmentVariablePrefix = "K8S_CLUSTER_UPGRADE_TOOL_"
+
+var environmentVariableNameRegex = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// Override is a value of the config which was read from an environment variable instead of the config file
+type Override struct {
+	// Path is the key of the value in the config, for example clusterlist[0].AwsRegion
+	Path                string
+	EnvironmentVariable string
+	Value               string
+}
+
+// EnvironmentVariableName returns the name of the environment variable overriding the value at the passed keys, for
+// example EnvironmentVariableName("components", "aws-node") returns K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_AWS_NODE
+func EnvironmentVariableName(keys ...string) string {
+	name := environmentVariableNameRegex.ReplaceAllString(strings.ToUpper(strings.Join(keys, "_")), "_")
+	return EnvironmentVariablePrefix + strings.Trim(name, "_")
+}
+
+// environmentOverride is a value of the config which can be overridden by an environment variable
+type environmentOverride struct {
+	path                string
+	environmentVariable string
+	apply               func(value string)
+}
+
+// applyEnvironmentOverrides overrides the values of the config set in the environment variables looked up with getenv,
+// and records every value overridden under Overrides. As the names of clusters and components which only differ in
+// the characters replaced by underscores map to the same environment variable, an environment variable set which
+// would override several values isn't applied, and an error is returned for it
+func (c *Configurations) applyEnvironmentOverrides(getenv func(string) string) ValidationErrors {
+	var overrides []environmentOverride
+	add := func(path string, apply func(value string), keys ...string) {
+		overrides = append(overrides, environmentOverride{path, EnvironmentVariableName(keys...), apply})
+	}
+	componentNames := c.componentNames()
+
+	for _, componentName := range componentNames {
+		add("components."+componentName, func(value string) {
+			if c.Components == nil {
+				c.Components = ComponentVersionConfigurations{}
+			}
+			c.Components[componentName] = value
+		}, "components", componentName)
+	}
+
+	minorVersions := make([]string, 0, len(c.ComponentMatrix))
+	for minorVersion := range c.ComponentMatrix {
+		minorVersions = append(minorVersions, minorVersion)
+	}
+	sort.Strings(minorVersions)
+	for _, minorVersion := range minorVersions {
+		for _, componentName := range componentNames {
+			path := fmt.Sprintf("componentmatrix[%q].%s", minorVersion, componentName)
+			add(path, func(value string) {
+				if c.ComponentMatrix[minorVersion] == nil {
+					c.ComponentMatrix[minorVersion] = ComponentVersionConfigurations{}
+				}
+				c.ComponentMatrix[minorVersion][componentName] = value
+			}, "componentmatrix", minorVersion, componentName)
+		}
+	}
+
+	for index := range c.ClusterList {
+		cluster := &c.ClusterList[index]
+		path := c.clusterPath(index, *cluster)
+		add(path+".AwsRegion", func(value string) { cluster.AwsRegion = value }, "cluster", cluster.ClusterName, "aws-region")
+		add(path+".AwsAccount", func(value string) { cluster.AwsAccount = value }, "cluster", cluster.ClusterName, "aws-account")
+		for _, componentName := range componentNames {
+			add(path+".ComponentVersions."+componentName, func(value string) {
+				if cluster.ComponentVersions == nil {
+					cluster.ComponentVersions = ComponentVersionConfigurations{}
+				}
+				cluster.ComponentVersions[componentName] = value
+			}, "cluster", cluster.ClusterName, "component-versions", componentName)
+		}
+	}
+
+	paths := map[string][]string{}
+	for _, override := range overrides {
+		paths[override.environmentVariable] = append(paths[override.environmentVariable], override.path)
+	}
+	var validationErrors ValidationErrors
+	reported := map[string]bool{}
+	for _, override := range overrides {
+		value := getenv(override.environmentVariable)
+		if value == "" || reported[override.environmentVariable] {
+			continue
+		}
+		if overriddenPaths := paths[override.environmentVariable]; len(overriddenPaths) > 1 {
+			validationErrors = append(validationErrors, ValidationError{override.path, fmt.Sprintf(
+				"can't be overridden by %s, which would also override %s as their names only differ in the characters "+
+					"replaced by underscores, please rename one of them", override.environmentVariable,
+				strings.Join(overriddenPaths[1:], ", "))})
+			reported[override.environmentVariable] = true
+			continue
+		}
+		override.apply(value)
+		c.Overrides = append(c.Overrides, Override{override.path, override.environmentVariable, value})
+	}
+	return validationErrors
+}
+
+// componentNames returns the names of every component configured, either with a version or with an object for a
+// cluster, in alphabetical order
+func (c Configurations) componentNames() []string {
+	componentVersions := c.Components.MergedWith(nil)
+	for _, row := range c.ComponentMatrix {
+		componentVersions = componentVersions.MergedWith(row)
+	}
+	for _, cluster := range c.ClusterList {
+		componentVersions = componentVersions.MergedWith(cluster.ComponentVersions)
+		for componentName := range cluster.Components {
+			componentVersions[componentName] = ""
+		}
+	}
+	return componentVersions.Names()
+}
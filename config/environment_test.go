@@ -0,0 +1,104 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironmentVariableName(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		{"when the component name has a dash", []string{"components", "aws-node"}, "K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_AWS_NODE"},
+		{"when the kubernetes version has a dot", []string{"componentmatrix", "1.27", "coredns"}, "K8S_CLUSTER_UPGRADE_TOOL_COMPONENTMATRIX_1_27_COREDNS"},
+		{"when the cluster name is mixed case", []string{"cluster", "Prod-eu.1", "aws-region"}, "K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_PROD_EU_1_AWS_REGION"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, EnvironmentVariableName(tt.keys...))
+		})
+	}
+}
+
+func TestConfigurations_applyEnvironmentOverrides(t *testing.T) {
+	configuration := func() Configurations {
+		return Configurations{
+			Components:      ComponentVersionConfigurations{"coredns": "v1.8.4", "kube-proxy": "v1.27.1"},
+			ComponentMatrix: map[string]ComponentVersionConfigurations{"1.27": {"coredns": "v1.10.1"}},
+			ClusterList: []ClusterListConfiguration{
+				{ClusterName: "prod-1", AwsRegion: "eu-west-1", AwsAccount: "prod",
+					Components: map[string]K8sObject{"aws-node": {}, "coredns": {}, "kube-proxy": {}}},
+			},
+		}
+	}
+	environment := func(variables map[string]string) func(string) string {
+		return func(key string) string { return variables[key] }
+	}
+
+	t.Run("when no environment variable is set the config is unchanged", func(t *testing.T) {
+		got := configuration()
+		err := got.applyEnvironmentOverrides(environment(nil))
+
+		assert.Nil(t, err)
+
+		assert.Equal(t, configuration(), got)
+	})
+
+	t.Run("when environment variables are set the values are overridden and recorded", func(t *testing.T) {
+		got := configuration()
+		err := got.applyEnvironmentOverrides(environment(map[string]string{
+			"K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_COREDNS":                            "v1.8.7",
+			"K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_AWS_NODE":                           "v1.12.0",
+			"K8S_CLUSTER_UPGRADE_TOOL_COMPONENTMATRIX_1_27_COREDNS":                  "v1.10.2",
+			"K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_PROD_1_AWS_REGION":                     "eu-central-1",
+			"K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_PROD_1_COMPONENT_VERSIONS_KUBE_PROXY":  "v1.27.4",
+			"K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_UNKNOWN_COMPONENT_VERSIONS_KUBE_PROXY": "v1.27.5",
+			"K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_UNCONFIGURED":                       "v1.0.0",
+		}))
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"aws-node": "v1.12.0", "coredns": "v1.8.7", "kube-proxy": "v1.27.1"}, got.Components)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.10.2"}, got.ComponentMatrix["1.27"])
+		assert.Equal(t, "eu-central-1", got.ClusterList[0].AwsRegion)
+		assert.Equal(t, "prod", got.ClusterList[0].AwsAccount)
+		assert.Equal(t, ComponentVersionConfigurations{"kube-proxy": "v1.27.4"}, got.ClusterList[0].ComponentVersions)
+		assert.Equal(t, []Override{
+			{"components.aws-node", "K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_AWS_NODE", "v1.12.0"},
+			{"components.coredns", "K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_COREDNS", "v1.8.7"},
+			{`componentmatrix["1.27"].coredns`, "K8S_CLUSTER_UPGRADE_TOOL_COMPONENTMATRIX_1_27_COREDNS", "v1.10.2"},
+			{"clusterlist[0].AwsRegion", "K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_PROD_1_AWS_REGION", "eu-central-1"},
+			{"clusterlist[0].ComponentVersions.kube-proxy", "K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_PROD_1_COMPONENT_VERSIONS_KUBE_PROXY", "v1.27.4"},
+		}, got.Overrides)
+	})
+
+	t.Run("when an environment variable set overrides several clusters it returns an error and isn't applied", func(t *testing.T) {
+		got := configuration()
+		got.ClusterList = append(got.ClusterList, ClusterListConfiguration{ClusterName: "prod_1", AwsRegion: "eu-west-1", AwsAccount: "prod"})
+
+		err := got.applyEnvironmentOverrides(environment(map[string]string{
+			"K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_PROD_1_AWS_REGION": "eu-central-1",
+			"K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_COREDNS":        "v1.8.7",
+		}))
+
+		assert.Equal(t, ValidationErrors{{"clusterlist[0].AwsRegion", "can't be overridden by " +
+			"K8S_CLUSTER_UPGRADE_TOOL_CLUSTER_PROD_1_AWS_REGION, which would also override clusterlist[1].AwsRegion as " +
+			"their names only differ in the characters replaced by underscores, please rename one of them"}}, err)
+		assert.Equal(t, "eu-west-1", got.ClusterList[0].AwsRegion)
+		assert.Equal(t, "eu-west-1", got.ClusterList[1].AwsRegion)
+		assert.Equal(t, []Override{
+			{"components.coredns", "K8S_CLUSTER_UPGRADE_TOOL_COMPONENTS_COREDNS", "v1.8.7"},
+		}, got.Overrides)
+	})
+
+	t.Run("when clusters map to the same environment variable which isn't set it returns no error", func(t *testing.T) {
+		got := configuration()
+		got.ClusterList = append(got.ClusterList, ClusterListConfiguration{ClusterName: "prod_1", AwsRegion: "eu-west-1", AwsAccount: "prod"})
+
+		err := got.applyEnvironmentOverrides(environment(nil))
+
+		assert.Nil(t, err)
+	})
+}
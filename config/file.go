@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeClusterIntoFile adds the cluster to the clusterlist of the config file at the passed path, replacing the
+// element with the same ClusterName if there is one. The file is created if it doesn't exist, comments in it are kept
+func MergeClusterIntoFile(path string, cluster ClusterListConfiguration) error {
+	document, err := readYamlFile(path)
+	if err != nil {
+		return err
+	}
+
+	var clusterNode yaml.Node
+	err = clusterNode.Encode(cluster)
+	if err != nil {
+		return errors.New("error marshaling the clusterlist element")
+	}
+
+	clusterList := mappingValue(document.Content[0], "clusterlist")
+	if clusterList == nil || clusterList.Kind != yaml.SequenceNode {
+		clusterList = setMappingValue(document.Content[0], "clusterlist", &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"})
+	}
+
+	for index, element := range clusterList.Content {
+		clusterName := mappingValue(element, "ClusterName")
+		if clusterName != nil && clusterName.Value == cluster.ClusterName {
+			clusterNode.HeadComment = element.HeadComment
+			clusterNode.LineComment = element.LineComment
+			clusterNode.FootComment = element.FootComment
+			clusterList.Content[index] = &clusterNode
+			return writeYamlFile(path, document)
+		}
+	}
+	clusterList.Content = append(clusterList.Content, &clusterNode)
+	return writeYamlFile(path, document)
+}
+
+// readYamlFile returns the document node of the YAML file at the passed path, or an empty document when the file
+// doesn't exist
+func readYamlFile(path string) (*yaml.Node, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.New("error reading from config file")
+	}
+
+	var document yaml.Node
+	err = yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, errors.New("error reading from config file")
+	}
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if document.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("error reading from config file, its top level is not a mapping")
+	}
+	return &document, nil
+}
+
+func writeYamlFile(path string, document *yaml.Node) error {
+	data, err := marshalYaml(document)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		return errors.New("error writing to config file")
+	}
+	return nil
+}
+
+func marshalYaml(value interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(value)
+	if err != nil {
+		return nil, errors.New("error marshaling config")
+	}
+	err = encoder.Close()
+	if err != nil {
+		return nil, errors.New("error marshaling config")
+	}
+	return buffer.Bytes(), nil
+}
+
+// mappingValue returns the value of the key in the mapping node, or nil when the key is not present
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			return mapping.Content[index+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value of the key in the mapping node, adding the key when it is not present
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) *yaml.Node {
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			mapping.Content[index+1] = value
+			return value
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+	return value
+}
+
+// MarshalClusterListElement returns the cluster as a YAML clusterlist element
+func MarshalClusterListElement(cluster ClusterListConfiguration) ([]byte, error) {
+	return marshalYaml([]ClusterListConfiguration{cluster})
+}
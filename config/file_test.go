@@ -0,0 +1,84 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeClusterIntoFile(t *testing.T) {
+	cluster := ClusterListConfiguration{
+		ClusterName: "cluster2",
+		AwsRegion:   "eu-west-1",
+		AwsAccount:  "account2",
+		Components: map[string]K8sObject{
+			"coredns": {DeploymentName: "coredns", ObjectType: "deployment", ContainerName: "coredns", Namespace: "kube-system"},
+		},
+	}
+	clusterYaml := `  - ClusterName: cluster2
+    AwsRegion: eu-west-1
+    AwsAccount: account2
+    Components:
+      coredns:
+        DeploymentName: coredns
+        ObjectType: deployment
+        ContainerName: coredns
+        Namespace: kube-system
+`
+
+	tests := []struct {
+		name      string
+		data      string
+		writeFile bool
+		want      string
+	}{
+		{"when the cluster is not present it is appended to the clusterlist and the comments are kept",
+			"# the versions to check\ncomponents:\n  coredns: v1.8.4 # latest\nclusterlist:\n- ClusterName: cluster1\n  AwsRegion: eu-west-1\n  AwsAccount: account1\n",
+			true,
+			"# the versions to check\ncomponents:\n  coredns: v1.8.4 # latest\nclusterlist:\n  - ClusterName: cluster1\n    AwsRegion: eu-west-1\n    AwsAccount: account1\n" + clusterYaml,
+		},
+		{"when the cluster is present it is replaced",
+			"components:\n  coredns: v1.8.4\nclusterlist:\n# the cluster to replace\n- ClusterName: cluster2\n  AwsRegion: us-east-1\n  AwsAccount: account1\n",
+			true,
+			"components:\n  coredns: v1.8.4\nclusterlist:\n  # the cluster to replace\n" + clusterYaml,
+		},
+		{"when the config file has no clusterlist it is added",
+			"components:\n  coredns: v1.8.4\n",
+			true,
+			"components:\n  coredns: v1.8.4\nclusterlist:\n" + clusterYaml,
+		},
+		{"when the config file doesn't exist it is created",
+			"", false,
+			"clusterlist:\n" + clusterYaml,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.writeFile {
+				err := ioutil.WriteFile(path, []byte(tt.data), 0644)
+				assert.Nil(t, err)
+			}
+
+			err := MergeClusterIntoFile(path, cluster)
+			assert.Nil(t, err)
+
+			got, err := ioutil.ReadFile(path)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("when the config file is not a mapping it returns an error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		err := ioutil.WriteFile(path, []byte("- foo\n"), 0644)
+		assert.Nil(t, err)
+
+		err = MergeClusterIntoFile(path, cluster)
+
+		assert.EqualError(t, err, "error reading from config file, its top level is not a mapping")
+	})
+}
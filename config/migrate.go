@@ -0,0 +1,204 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the version of the config file schema the tool reads, config files of older versions are migrated
+// to it when they are read. The versions are
+// 1: v0.1.0, clusters are named with Name and objects are configured with type and name
+// 2: v0.2.0, clusters are named with ClusterName and objects are configured with ObjectType, DeploymentName,
+// ContainerName and Namespace under AwsNodeObject, ClusterAutoscalerObject, CoreDnsObject and KubeProxyObject
+// 3: objects of any number of components are configured under Components
+const CurrentVersion = 3
+
+const migratedComment = "added by config migrate, please verify"
+
+// legacyComponentObjectKeys are the keys the objects of the components were configured with up to version 2
+var legacyComponentObjectKeys = []struct {
+	key           string
+	componentName string
+}{
+	{"AwsNodeObject", "aws-node"},
+	{"ClusterAutoscalerObject", "cluster-autoscaler"},
+	{"CoreDnsObject", "coredns"},
+	{"KubeProxyObject", "kube-proxy"},
+}
+
+// migrations maps a version to the function migrating the root mapping of a config file from it to the next version
+var migrations = map[int]func(root *yaml.Node){
+	1: migrateFromVersion1,
+	2: migrateFromVersion2,
+}
+
+// Migrate returns the contents of a config file rewritten to the CurrentVersion of the schema along with the version
+// the contents were at, keeping the comments
+func Migrate(data []byte) (migrated []byte, fromVersion int, err error) {
+	var document yaml.Node
+	err = yaml.Unmarshal(data, &document)
+	if err != nil || document.Kind != yaml.DocumentNode || document.Content[0].Kind != yaml.MappingNode {
+		return nil, 0, errors.New("error reading from config file")
+	}
+	root := document.Content[0]
+
+	fromVersion, err = detectVersion(root)
+	if err != nil {
+		return nil, 0, err
+	}
+	if fromVersion == CurrentVersion {
+		return data, fromVersion, nil
+	}
+
+	for version := fromVersion; version < CurrentVersion; version++ {
+		migrations[version](root)
+	}
+	setVersion(root, CurrentVersion)
+
+	migrated, err = marshalYaml(&document)
+	if err != nil {
+		return nil, 0, err
+	}
+	return migrated, fromVersion, nil
+}
+
+// detectVersion returns the version set in the config file, config files written before the version was added are
+// recognised by their keys
+func detectVersion(root *yaml.Node) (int, error) {
+	if versionNode := mappingValue(root, "version"); versionNode != nil {
+		version, err := strconv.Atoi(versionNode.Value)
+		if err != nil || version < 1 {
+			return 0, fmt.Errorf("invalid config file version %s", versionNode.Value)
+		}
+		if version > CurrentVersion {
+			return 0, fmt.Errorf("the config file version %d is newer than the version %d supported, please update k8s-cluster-upgrade-tool", version, CurrentVersion)
+		}
+		return version, nil
+	}
+
+	version := CurrentVersion
+	for _, cluster := range clusterListElements(root) {
+		if mappingValue(cluster, "Name") != nil {
+			return 1, nil
+		}
+		for _, legacyKey := range legacyComponentObjectKeys {
+			legacyObject := mappingValue(cluster, legacyKey.key)
+			if legacyObject == nil {
+				continue
+			}
+			if mappingValue(legacyObject, "type") != nil || mappingValue(legacyObject, "name") != nil {
+				return 1, nil
+			}
+			version = 2
+		}
+	}
+	return version, nil
+}
+
+// migrateFromVersion1 renames Name to ClusterName, type to ObjectType and name to DeploymentName, and adds the
+// ContainerName and Namespace of the objects, which used to be the name of the object and kube-system
+func migrateFromVersion1(root *yaml.Node) {
+	for _, cluster := range clusterListElements(root) {
+		renameMappingKey(cluster, "Name", "ClusterName")
+		for _, legacyKey := range legacyComponentObjectKeys {
+			legacyObject := mappingValue(cluster, legacyKey.key)
+			if legacyObject == nil || legacyObject.Kind != yaml.MappingNode {
+				continue
+			}
+			renameMappingKey(legacyObject, "type", "ObjectType")
+			renameMappingKey(legacyObject, "name", "DeploymentName")
+
+			deploymentName := mappingValue(legacyObject, "DeploymentName")
+			if mappingValue(legacyObject, "ContainerName") == nil && deploymentName != nil {
+				addMigratedScalar(legacyObject, "ContainerName", deploymentName.Value)
+			}
+			if mappingValue(legacyObject, "Namespace") == nil {
+				addMigratedScalar(legacyObject, "Namespace", "kube-system")
+			}
+		}
+	}
+}
+
+// migrateFromVersion2 moves the objects configured under AwsNodeObject, ClusterAutoscalerObject, CoreDnsObject and
+// KubeProxyObject under Components
+func migrateFromVersion2(root *yaml.Node) {
+	for _, cluster := range clusterListElements(root) {
+		for _, legacyKey := range legacyComponentObjectKeys {
+			keyIndex := mappingKeyIndex(cluster, legacyKey.key)
+			if keyIndex == -1 {
+				continue
+			}
+			keyNode, valueNode := cluster.Content[keyIndex], cluster.Content[keyIndex+1]
+			cluster.Content = append(cluster.Content[:keyIndex], cluster.Content[keyIndex+2:]...)
+
+			components := mappingValue(cluster, "Components")
+			if components == nil {
+				components = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				componentsKey := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "Components"}
+				cluster.Content = append(cluster.Content[:keyIndex],
+					append([]*yaml.Node{componentsKey, components}, cluster.Content[keyIndex:]...)...)
+			}
+			if mappingValue(components, legacyKey.componentName) != nil {
+				continue
+			}
+			components.Content = append(components.Content, &yaml.Node{
+				Kind: yaml.ScalarNode, Tag: "!!str", Value: legacyKey.componentName,
+				HeadComment: keyNode.HeadComment, LineComment: keyNode.LineComment, FootComment: keyNode.FootComment,
+			}, valueNode)
+		}
+	}
+}
+
+// clusterListElements returns the mapping nodes of the clusterlist of the config file
+func clusterListElements(root *yaml.Node) []*yaml.Node {
+	clusterList := mappingValue(root, "clusterlist")
+	if clusterList == nil || clusterList.Kind != yaml.SequenceNode {
+		return nil
+	}
+	var clusters []*yaml.Node
+	for _, cluster := range clusterList.Content {
+		if cluster.Kind == yaml.MappingNode {
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters
+}
+
+// mappingKeyIndex returns the index of the key node in the content of the mapping node, or -1 when the key is not
+// present
+func mappingKeyIndex(mapping *yaml.Node, key string) int {
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			return index
+		}
+	}
+	return -1
+}
+
+func renameMappingKey(mapping *yaml.Node, oldKey, newKey string) {
+	if keyIndex := mappingKeyIndex(mapping, oldKey); keyIndex != -1 && mappingKeyIndex(mapping, newKey) == -1 {
+		mapping.Content[keyIndex].Value = newKey
+	}
+}
+
+func addMigratedScalar(mapping *yaml.Node, key, value string) {
+	setMappingValue(mapping, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value, LineComment: migratedComment})
+}
+
+// setVersion sets the version of the config file, which is added as its first key
+func setVersion(root *yaml.Node, version int) {
+	versionNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+	if keyIndex := mappingKeyIndex(root, "version"); keyIndex != -1 {
+		root.Content[keyIndex+1] = versionNode
+		return
+	}
+	versionKey := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}
+	// the comment at the top of the config file stays at its top
+	if len(root.Content) != 0 {
+		versionKey.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{versionKey, versionNode}, root.Content...)
+}
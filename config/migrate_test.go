@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	currentVersionData := "version: 3\ncomponents:\n  coredns: v1.8.4\nclusterlist:\n- ClusterName: cluster1\n  Components:\n    coredns:\n      DeploymentName: coredns\n"
+
+	tests := []struct {
+		name            string
+		data            string
+		wantMigrated    string
+		wantFromVersion int
+		err             error
+	}{
+		{"when the config file is of version 1 it renames the keys, adds the container name and namespace and moves the objects under Components",
+			"# the versions to check\ncomponents:\n  coredns: v1.8.4\nclusterlist:\n- Name: cluster1\n  AwsRegion: eu-west-1\n  # the dns of the cluster\n  CoreDnsObject:\n    type: deployment\n    name: coredns\n",
+			"# the versions to check\nversion: 3\ncomponents:\n  coredns: v1.8.4\nclusterlist:\n  - ClusterName: cluster1\n    AwsRegion: eu-west-1\n    Components:\n      # the dns of the cluster\n      coredns:\n        ObjectType: deployment\n        DeploymentName: coredns\n        ContainerName: coredns # added by config migrate, please verify\n        Namespace: kube-system # added by config migrate, please verify\n",
+			1, nil},
+		{"when the config file is of version 2 it moves the objects under Components in the order of the legacy keys",
+			"components:\n  coredns: v1.8.4\nclusterlist:\n- ClusterName: cluster1\n  KubeProxyObject:\n    DeploymentName: kube-proxy\n  AwsNodeObject:\n    DeploymentName: aws-node # the cni\n  ComponentVersions:\n    coredns: v1.8.3\n",
+			"version: 3\ncomponents:\n  coredns: v1.8.4\nclusterlist:\n  - ClusterName: cluster1\n    Components:\n      aws-node:\n        DeploymentName: aws-node # the cni\n      kube-proxy:\n        DeploymentName: kube-proxy\n    ComponentVersions:\n      coredns: v1.8.3\n",
+			2, nil},
+		{"when the config file of version 2 already has a Components key the legacy objects are added to it",
+			"clusterlist:\n- ClusterName: cluster1\n  Components:\n    metrics-server:\n      DeploymentName: metrics-server\n  CoreDnsObject:\n    DeploymentName: coredns\n",
+			"version: 3\nclusterlist:\n  - ClusterName: cluster1\n    Components:\n      metrics-server:\n        DeploymentName: metrics-server\n      coredns:\n        DeploymentName: coredns\n",
+			2, nil},
+		{"when the config file has an older version set it is migrated from it",
+			"version: 2\nclusterlist:\n- ClusterName: cluster1\n",
+			"version: 3\nclusterlist:\n  - ClusterName: cluster1\n",
+			2, nil},
+		{"when the config file is of the current version it is returned unchanged",
+			currentVersionData, currentVersionData, 3, nil},
+		{"when the config file has no version and no legacy keys it is of the current version",
+			"components:\n  coredns: v1.8.4\n", "components:\n  coredns: v1.8.4\n", 3, nil},
+		{"when the version of the config file is newer than the current version it returns an error",
+			"version: 4\n", "", 0, errors.New("the config file version 4 is newer than the version 3 supported, please update k8s-cluster-upgrade-tool")},
+		{"when the version of the config file is not a number it returns an error",
+			"version: latest\n", "", 0, errors.New("invalid config file version latest")},
+		{"when the config file is not a mapping it returns an error",
+			"- foo\n", "", 0, errors.New("error reading from config file")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrated, fromVersion, err := Migrate([]byte(tt.data))
+			assert.Equal(t, tt.wantMigrated, string(migrated))
+			assert.Equal(t, tt.wantFromVersion, fromVersion)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
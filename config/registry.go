@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// eksRegistryAccounts maps AWS regions to the account of the ECR registry the images of the EKS add-ons, like aws-node,
+// kube-proxy and coredns, are pulled from in the region, reference:
+// https://docs.aws.amazon.com/eks/latest/userguide/add-ons-images.html
+var eksRegistryAccounts = map[string]string{
+	"af-south-1":     "877085696533",
+	"ap-east-1":      "800184023465",
+	"ap-northeast-1": "602401143452",
+	"ap-northeast-2": "602401143452",
+	"ap-northeast-3": "602401143452",
+	"ap-south-1":     "602401143452",
+	"ap-south-2":     "900889452093",
+	"ap-southeast-1": "602401143452",
+	"ap-southeast-2": "602401143452",
+	"ap-southeast-3": "296578399912",
+	"ap-southeast-4": "491585149902",
+	"ca-central-1":   "602401143452",
+	"ca-west-1":      "761377655185",
+	"cn-north-1":     "918309763551",
+	"cn-northwest-1": "961992271922",
+	"eu-central-1":   "602401143452",
+	"eu-central-2":   "900612956339",
+	"eu-north-1":     "602401143452",
+	"eu-south-1":     "590381155156",
+	"eu-south-2":     "455263428931",
+	"eu-west-1":      "602401143452",
+	"eu-west-2":      "602401143452",
+	"eu-west-3":      "602401143452",
+	"il-central-1":   "066635153087",
+	"me-central-1":   "759879836304",
+	"me-south-1":     "558608220178",
+	"sa-east-1":      "602401143452",
+	"us-east-1":      "602401143452",
+	"us-east-2":      "602401143452",
+	"us-gov-east-1":  "151742754352",
+	"us-gov-west-1":  "013241004608",
+	"us-west-1":      "602401143452",
+	"us-west-2":      "602401143452",
+}
+
+var ecrRegistryRegex = regexp.MustCompile(`^([0-9]{12})\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+
+// GetEksRegistry returns the ECR registry the images of the EKS add-ons are pulled from in the region, for example
+// 602401143452.dkr.ecr.eu-west-1.amazonaws.com. The registries configured under the eksregistries key take precedence
+// over the ones built into the tool
+func (c Configurations) GetEksRegistry(region string) (string, error) {
+	if registry, present := c.EksRegistries[region]; present {
+		return registry, nil
+	}
+	account, present := eksRegistryAccounts[region]
+	if !present {
+		return "", fmt.Errorf("no EKS registry is known for the region %s, please configure it under the eksregistries key", region)
+	}
+	registry := fmt.Sprintf("%s.dkr.ecr.%s.amazonaws.com", account, region)
+	if strings.HasPrefix(region, "cn-") {
+		registry += ".cn"
+	}
+	return registry, nil
+}
+
+// isEksRegistry returns whether the registry is the one the images of the EKS add-ons are pulled from in any region
+func (c Configurations) isEksRegistry(registry string) bool {
+	for _, eksRegistry := range c.EksRegistries {
+		if registry == eksRegistry {
+			return true
+		}
+	}
+	match := ecrRegistryRegex.FindStringSubmatch(registry)
+	return match != nil && eksRegistryAccounts[match[2]] == match[1]
+}
+
+// GetEksImageForCluster returns the image repository with its registry replaced by the EKS registry of the region of
+// the cluster, and whether the image repository is pulled from an EKS registry at all. Image repositories of other
+// registries, like mirrors, are returned unchanged
+func (c Configurations) GetEksImageForCluster(clusterName, imageRepository string) (string, bool, error) {
+	parts := strings.SplitN(imageRepository, "/", 2)
+	if len(parts) != 2 || !c.isEksRegistry(parts[0]) {
+		return imageRepository, false, nil
+	}
+	_, awsRegion, err := c.GetAwsAccountAndRegionForCluster(clusterName)
+	if err != nil {
+		return "", false, err
+	}
+	registry, err := c.GetEksRegistry(awsRegion)
+	if err != nil {
+		return "", false, err
+	}
+	return registry + "/" + parts[1], true, nil
+}
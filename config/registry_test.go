@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurations_GetEksRegistry(t *testing.T) {
+	configuration := Configurations{EksRegistries: map[string]string{"eu-west-1": "mirror.example.com"}}
+	tests := []struct {
+		name   string
+		region string
+		want   string
+		err    error
+	}{
+		{"when the region uses the default EKS account", "us-east-1", "602401143452.dkr.ecr.us-east-1.amazonaws.com", nil},
+		{"when the region uses its own EKS account", "ap-east-1", "800184023465.dkr.ecr.ap-east-1.amazonaws.com", nil},
+		{"when the region is a china region", "cn-north-1", "918309763551.dkr.ecr.cn-north-1.amazonaws.com.cn", nil},
+		{"when the registry of the region is configured", "eu-west-1", "mirror.example.com", nil},
+		{"when the region is unknown it returns an error", "xx-west-1", "",
+			errors.New("no EKS registry is known for the region xx-west-1, please configure it under the eksregistries key")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := configuration.GetEksRegistry(tt.region)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestConfigurations_GetEksImageForCluster(t *testing.T) {
+	configuration := Configurations{
+		ClusterList: []ClusterListConfiguration{
+			{ClusterName: "eu-cluster", AwsRegion: "eu-west-1"},
+			{ClusterName: "af-cluster", AwsRegion: "af-south-1"},
+		},
+	}
+	tests := []struct {
+		name            string
+		clusterName     string
+		imageRepository string
+		want            string
+		wantEksImage    bool
+		err             error
+	}{
+		{"when the image is pulled from the EKS registry of the region of the cluster it is unchanged", "eu-cluster",
+			"602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/coredns",
+			"602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/coredns", true, nil},
+		{"when the image is pulled from the EKS registry of another region its registry is replaced", "af-cluster",
+			"602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/kube-proxy",
+			"877085696533.dkr.ecr.af-south-1.amazonaws.com/eks/kube-proxy", true, nil},
+		{"when the image is pulled from an ECR registry which isn't an EKS registry it is unchanged", "af-cluster",
+			"123456789012.dkr.ecr.eu-west-1.amazonaws.com/mirror/coredns",
+			"123456789012.dkr.ecr.eu-west-1.amazonaws.com/mirror/coredns", false, nil},
+		{"when the image is pulled from another registry it is unchanged", "af-cluster",
+			"public.ecr.aws/eks/coredns", "public.ecr.aws/eks/coredns", false, nil},
+		{"when the cluster name is not valid it returns an error", "us-cluster",
+			"602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/coredns", "", false,
+			errors.New("no awsAccount and awsRegion was found for the passed clusterName")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotEksImage, err := configuration.GetEksImageForCluster(tt.clusterName, tt.imageRepository)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantEksImage, gotEksImage)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sha256EnvironmentVariable holds the SHA-256 checksum a config file fetched from a remote location needs to match
+const Sha256EnvironmentVariable = "K8S_CLUSTER_UPGRADE_TOOL_CONFIG_SHA256"
+
+// RemoteFile is a config file fetched from a remote location, NotModified is set instead of Data when the ETag passed
+// to fetch it is still the current one
+type RemoteFile struct {
+	Data        []byte
+	ETag        string
+	NotModified bool
+}
+
+// RemoteFetcher fetches config files from the remote locations of a URL scheme, like https or s3
+type RemoteFetcher interface {
+	Fetch(location, etag string) (RemoteFile, error)
+}
+
+// RemoteSource is the remote location a config file was fetched from
+type RemoteSource struct {
+	// URL is the location the config file was fetched from
+	URL string
+	// FetchError is the error fetching the config file failed with when the last good copy of the cache was read instead
+	FetchError error
+	// NotModified is set when the cached copy was read as the config file wasn't modified since it was cached
+	NotModified bool
+}
+
+// httpFetchTimeout is how long fetching a config file from an http or https URL may take before the last good copy is
+// read instead
+const httpFetchTimeout = 30 * time.Second
+
+// HTTPFetcher fetches config files from http and https URLs, with a client timing out after httpFetchTimeout when no
+// Client is set
+type HTTPFetcher struct {
+	Client *http.Client
+}
+
+func (h HTTPFetcher) Fetch(location, etag string) (RemoteFile, error) {
+	request, err := http.NewRequest(http.MethodGet, location, nil)
+	if err != nil {
+		return RemoteFile{}, err
+	}
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: httpFetchTimeout}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return RemoteFile{}, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		return RemoteFile{ETag: etag, NotModified: true}, nil
+	case http.StatusOK:
+		data, err := ioutil.ReadAll(response.Body)
+		if err != nil {
+			return RemoteFile{}, err
+		}
+		return RemoteFile{Data: data, ETag: response.Header.Get("ETag")}, nil
+	default:
+		return RemoteFile{}, fmt.Errorf("unexpected status %s", response.Status)
+	}
+}
+
+// IsRemote returns whether the path of the config file is a URL of a remote location, rather than a local path
+func IsRemote(path string) bool {
+	scheme := strings.SplitN(path, "://", 2)[0]
+	return scheme != path && !strings.ContainsAny(scheme, "/\\")
+}
+
+// remoteCache is the state of the cache of a remote location, which holds the last good copy of the config file along
+// with its ETag
+type remoteCache struct {
+	File string `json:"file"`
+	ETag string `json:"etag"`
+}
+
+// fetchRemote fetches the config file at the remote location into the cache and returns the path of the copy to read.
+// The last good copy is read when the config file wasn't modified since it was cached, or when fetching it fails. A
+// copy which was fetched only becomes the last good copy once commit is called, after it was read successfully
+func (l Loader) fetchRemote(location string) (path string, source RemoteSource, commit func() error, err error) {
+	source = RemoteSource{URL: location}
+	commit = func() error { return nil }
+	scheme := strings.SplitN(location, "://", 2)[0]
+	fetcher, present := l.Fetchers[scheme]
+	if !present && (scheme == "http" || scheme == "https") {
+		fetcher, present = HTTPFetcher{}, true
+	}
+	if !present {
+		return "", source, commit, fmt.Errorf("config files can't be fetched from %s URLs", scheme)
+	}
+
+	locationChecksum := sha256.Sum256([]byte(location))
+	cacheDir := filepath.Join(l.cacheDir(), "remote", hex.EncodeToString(locationChecksum[:8]))
+	statePath := filepath.Join(cacheDir, "cache.json")
+	var cache remoteCache
+	var cachedData []byte
+	if stateData, err := ioutil.ReadFile(statePath); err == nil && json.Unmarshal(stateData, &cache) == nil {
+		cachedData, err = ioutil.ReadFile(filepath.Join(cacheDir, cache.File))
+		if err != nil {
+			cache = remoteCache{}
+		}
+	}
+
+	file, fetchErr := fetcher.Fetch(location, cache.ETag)
+	if fetchErr == nil && file.NotModified && cache.File == "" {
+		fetchErr = errors.New("it was reported as not modified while no copy of it is cached")
+	}
+	if fetchErr != nil || file.NotModified {
+		if cache.File == "" {
+			return "", source, commit, fmt.Errorf("error fetching the config file %s: %s", location, fetchErr)
+		}
+		if err := l.verifySha256(cachedData); err != nil {
+			return "", source, commit, fmt.Errorf("the cached copy of the config file %s %s", location, err)
+		}
+		source.FetchError, source.NotModified = fetchErr, file.NotModified
+		return filepath.Join(cacheDir, cache.File), source, commit, nil
+	}
+
+	if err := l.verifySha256(file.Data); err != nil {
+		return "", source, commit, fmt.Errorf("the config file %s %s", location, err)
+	}
+	dataChecksum := sha256.Sum256(file.Data)
+	fetched := remoteCache{File: fmt.Sprintf("%s-%s.%s", FileName, hex.EncodeToString(dataChecksum[:8]), FileType), ETag: file.ETag}
+	err = os.MkdirAll(cacheDir, 0755)
+	if err == nil {
+		err = writeFileAtomically(filepath.Join(cacheDir, fetched.File), file.Data)
+	}
+	if err != nil {
+		return "", source, commit, fmt.Errorf("error caching the config file %s: %s", location, err)
+	}
+
+	commit = func() error {
+		stateData, err := json.Marshal(fetched)
+		if err == nil {
+			err = writeFileAtomically(statePath, stateData)
+		}
+		if err != nil {
+			return fmt.Errorf("error caching the config file %s: %s", location, err)
+		}
+		if cache.File != "" && cache.File != fetched.File {
+			// the previous copy is no longer referenced by the state, it is only left behind when it can't be removed
+			err = os.Remove(filepath.Join(cacheDir, cache.File))
+			if err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error removing the previous cached copy of the config file %s: %s", location, err)
+			}
+		}
+		return nil
+	}
+	return filepath.Join(cacheDir, fetched.File), source, commit, nil
+}
+
+// verifySha256 returns an error when a SHA-256 checksum is pinned and the data doesn't match it
+func (l Loader) verifySha256(data []byte) error {
+	pinned := l.Sha256
+	if pinned == "" {
+		pinned = l.getenv(Sha256EnvironmentVariable)
+	}
+	if pinned == "" {
+		return nil
+	}
+	checksum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(checksum[:]), pinned) {
+		return errors.New("doesn't match the SHA-256 checksum " + pinned)
+	}
+	return nil
+}
+
+// cacheDir returns the directory config files fetched from remote locations are cached in, the
+// k8s-cluster-upgrade-tool directory of the XDG cache directory, which defaults to $HOME/.cache
+func (l Loader) cacheDir() string {
+	if l.CacheDir != "" {
+		return l.CacheDir
+	}
+	xdgCacheHome := l.getenv("XDG_CACHE_HOME")
+	if xdgCacheHome == "" {
+		xdgCacheHome = filepath.Join(l.getenv("HOME"), ".cache")
+	}
+	return filepath.Join(xdgCacheHome, "k8s-cluster-upgrade-tool")
+}
+
+// writeFileAtomically writes the file by renaming a temporary file, so a failed write never leaves a partial file behind
+func writeFileAtomically(path string, data []byte) error {
+	temporaryPath := path + ".tmp"
+	if err := ioutil.WriteFile(temporaryPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, path)
+}
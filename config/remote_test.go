@@ -0,0 +1,242 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestIsRemote(t *testing.T) {
+	tests := []struct {
+		path   string
+		result bool
+	}{
+		{"https://example.com/config.yaml", true},
+		{"http://example.com/config.yaml", true},
+		{"s3://bucket/config.yaml", true},
+		{"/etc/k8s-cluster-upgrade-tool/config.yaml", false},
+		{"config.yaml", false},
+		{"path/to://config.yaml", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.result, IsRemote(tt.path))
+		})
+	}
+}
+
+// remoteConfigServer serves the config file it holds with its ETag, answering requests passing the current ETag with
+// 304 Not Modified, and every request with 503 Service Unavailable while it is down
+type remoteConfigServer struct {
+	data     string
+	down     bool
+	requests []string
+}
+
+func (s *remoteConfigServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests = append(s.requests, r.Header.Get("If-None-Match"))
+	if s.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	checksum := sha256.Sum256([]byte(s.data))
+	etag := fmt.Sprintf("%q", hex.EncodeToString(checksum[:8]))
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	fmt.Fprint(w, s.data)
+}
+
+type fetcherFunc func(location, etag string) (RemoteFile, error)
+
+func (f fetcherFunc) Fetch(location, etag string) (RemoteFile, error) {
+	return f(location, etag)
+}
+
+func TestLoader_Load_Remote(t *testing.T) {
+	configYaml := func(version string) string {
+		return fmt.Sprintf("components:\n  coredns: %q\nclusterlist:\n- ClusterName: \"cluster1\"\n  AwsRegion: \"region1\"\n  AwsAccount: \"account1\"\n  Components:\n    coredns:\n      ObjectType: \"deployment\"\n      DeploymentName: \"coredns\"\n      ContainerName: \"coredns\"\n      Namespace: \"kube-system\"\n", version)
+	}
+	sha256Of := func(data string) string {
+		checksum := sha256.Sum256([]byte(data))
+		return hex.EncodeToString(checksum[:])
+	}
+	newServer := func(t *testing.T, data string) (*remoteConfigServer, string) {
+		server := &remoteConfigServer{data: data}
+		httpServer := httptest.NewServer(server)
+		t.Cleanup(httpServer.Close)
+		return server, httpServer.URL + "/config.yaml"
+	}
+
+	t.Run("when the config file is fetched it is read from the cache", func(t *testing.T) {
+		_, url := newServer(t, configYaml("v1.8.4"))
+		loader := Loader{CacheDir: t.TempDir()}
+
+		got, err := loader.Load(url)
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.4"}, got.Components)
+		assert.Equal(t, &RemoteSource{URL: url}, got.Remote)
+		assert.FileExists(t, got.File)
+	})
+
+	t.Run("when the config file wasn't modified since it was cached the cached copy is read", func(t *testing.T) {
+		server, url := newServer(t, configYaml("v1.8.4"))
+		loader := Loader{CacheDir: t.TempDir()}
+
+		_, err := loader.Load(url)
+		assert.Nil(t, err)
+		got, err := loader.Load(url)
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.4"}, got.Components)
+		assert.Equal(t, &RemoteSource{URL: url, NotModified: true}, got.Remote)
+		assert.Equal(t, "", server.requests[0])
+		assert.NotEqual(t, "", server.requests[1])
+	})
+
+	t.Run("when the config file was modified since it was cached the new config file is read", func(t *testing.T) {
+		server, url := newServer(t, configYaml("v1.8.4"))
+		loader := Loader{CacheDir: t.TempDir()}
+
+		first, err := loader.Load(url)
+		assert.Nil(t, err)
+		server.data = configYaml("v1.9.3")
+		got, err := loader.Load(url)
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.9.3"}, got.Components)
+		assert.Equal(t, &RemoteSource{URL: url}, got.Remote)
+		assert.NoFileExists(t, first.File)
+	})
+
+	t.Run("when the previous copy is already gone once the modified config file is cached it is read", func(t *testing.T) {
+		server, url := newServer(t, configYaml("v1.8.4"))
+		loader := Loader{CacheDir: t.TempDir()}
+
+		first, err := loader.Load(url)
+		assert.Nil(t, err)
+		assert.Nil(t, os.Remove(first.File))
+		server.data = configYaml("v1.9.3")
+		got, err := loader.Load(url)
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.9.3"}, got.Components)
+	})
+
+	t.Run("when fetching the config file fails the last good copy is read", func(t *testing.T) {
+		server, url := newServer(t, configYaml("v1.8.4"))
+		loader := Loader{CacheDir: t.TempDir()}
+
+		_, err := loader.Load(url)
+		assert.Nil(t, err)
+		server.down = true
+		got, err := loader.Load(url)
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.4"}, got.Components)
+		assert.Equal(t, url, got.Remote.URL)
+		assert.EqualError(t, got.Remote.FetchError, "unexpected status 503 Service Unavailable")
+	})
+
+	t.Run("when fetching the config file fails and it was never cached an error is returned", func(t *testing.T) {
+		server, url := newServer(t, configYaml("v1.8.4"))
+		server.down = true
+		loader := Loader{CacheDir: t.TempDir()}
+
+		_, err := loader.Load(url)
+
+		assert.EqualError(t, err, fmt.Sprintf("error fetching the config file %s: unexpected status 503 Service Unavailable", url))
+	})
+
+	t.Run("when the fetched config file is invalid an error is returned and the last good copy is kept", func(t *testing.T) {
+		server, url := newServer(t, configYaml("v1.8.4"))
+		loader := Loader{CacheDir: t.TempDir()}
+
+		_, err := loader.Load(url)
+		assert.Nil(t, err)
+		server.data = configYaml("~latest")
+		_, err = loader.Load(url)
+		assert.Equal(t, ValidationErrors{{"components.coredns", "invalid constraint ~latest, invalid version latest"}}, err)
+		server.down = true
+		got, err := loader.Load(url)
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.4"}, got.Components)
+	})
+
+	t.Run("when the config file matches the pinned SHA-256 checksum it is read", func(t *testing.T) {
+		_, url := newServer(t, configYaml("v1.8.4"))
+		loader := Loader{CacheDir: t.TempDir(), Sha256: sha256Of(configYaml("v1.8.4"))}
+
+		got, err := loader.Load(url)
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.4"}, got.Components)
+	})
+
+	t.Run("when the config file doesn't match the SHA-256 checksum of the environment variable an error is returned", func(t *testing.T) {
+		_, url := newServer(t, configYaml("v1.8.4"))
+		pinned := sha256Of(configYaml("v1.9.3"))
+		loader := Loader{CacheDir: t.TempDir(), Getenv: func(key string) string {
+			return map[string]string{Sha256EnvironmentVariable: pinned}[key]
+		}}
+
+		_, err := loader.Load(url)
+
+		assert.EqualError(t, err, fmt.Sprintf("the config file %s doesn't match the SHA-256 checksum %s", url, pinned))
+	})
+
+	t.Run("when the last good copy doesn't match the pinned SHA-256 checksum an error is returned", func(t *testing.T) {
+		server, url := newServer(t, configYaml("v1.8.4"))
+		cacheDir := t.TempDir()
+
+		_, err := Loader{CacheDir: cacheDir}.Load(url)
+		assert.Nil(t, err)
+		server.down = true
+		pinned := sha256Of(configYaml("v1.9.3"))
+		_, err = Loader{CacheDir: cacheDir, Sha256: pinned}.Load(url)
+
+		assert.EqualError(t, err, fmt.Sprintf("the cached copy of the config file %s doesn't match the SHA-256 checksum %s", url, pinned))
+	})
+
+	t.Run("when a fetcher is registered for the scheme of the URL it fetches the config file", func(t *testing.T) {
+		loader := Loader{CacheDir: t.TempDir(), Fetchers: map[string]RemoteFetcher{
+			"s3": fetcherFunc(func(location, etag string) (RemoteFile, error) {
+				return RemoteFile{Data: []byte(configYaml("v1.8.4")), ETag: "etag"}, nil
+			}),
+		}}
+
+		got, err := loader.Load("s3://bucket/config.yaml")
+
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.4"}, got.Components)
+	})
+
+	t.Run("when the config file is reported as not modified and it was never cached an error is returned", func(t *testing.T) {
+		loader := Loader{CacheDir: t.TempDir(), Fetchers: map[string]RemoteFetcher{
+			"s3": fetcherFunc(func(location, etag string) (RemoteFile, error) {
+				return RemoteFile{NotModified: true}, nil
+			}),
+		}}
+
+		_, err := loader.Load("s3://bucket/config.yaml")
+
+		assert.EqualError(t, err, "error fetching the config file s3://bucket/config.yaml: it was reported as not modified "+
+			"while no copy of it is cached")
+	})
+
+	t.Run("when no fetcher is registered for the scheme of the URL an error is returned", func(t *testing.T) {
+		_, err := Loader{CacheDir: t.TempDir()}.Load("ftp://example.com/config.yaml")
+
+		assert.Equal(t, errors.New("config files can't be fetched from ftp URLs"), err)
+	})
+}
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// awsRegionPattern matches the names of AWS regions, for example eu-west-1, us-gov-east-1 or cn-north-1
+const awsRegionPattern = `^[a-z]{2}(-[a-z]+)+-[0-9]+$`
+
+// JSONSchema is a JSON Schema, draft-07, describing the config file
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	PropertyNames        *JSONSchema            `json:"propertyNames,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	MinLength            int                    `json:"minLength,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Maximum              *int                   `json:"maximum,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty"`
+	Not                  *JSONSchema            `json:"not,omitempty"`
+	If                   *JSONSchema            `json:"if,omitempty"`
+	Then                 *JSONSchema            `json:"then,omitempty"`
+}
+
+// schemaAnnotations hold what the mapstructure tags of the fields of the config types don't tell about their keys,
+// they are keyed by the name of the type and the field, for example K8sObject.ObjectType
+var schemaAnnotations = map[string]JSONSchema{
+	"Configurations.Components": {
+		Description: "versions of the components, which are either a version the component needs to run exactly or a semantic version constraint like \">=1.10.1 <1.11\"",
+	},
+	"Configurations.Defaults": {
+		Description: "deep-merged into every element of the clusterlist, which only needs to set what differs from them",
+	},
+	"Configurations.ClusterList": {Description: "clusters the tool runs against"},
+	"Configurations.Images": {
+		Description: "image repositories, without tag, of the components, with the {region}, {account} and {eks-registry} placeholders",
+	},
+	"Configurations.EksRegistries": {
+		Description:   "ECR registries the images of the EKS add-ons are pulled from, per AWS region",
+		PropertyNames: &JSONSchema{Pattern: awsRegionPattern},
+	},
+	"Configurations.Groups":                {Description: "named sets of clusters, which can be targeted with --group"},
+	"ClusterListConfiguration.ClusterName": {Description: "name of the EKS cluster, which is also the name of its kube context unless KubeContext is set"},
+	"ClusterListConfiguration.KubeContext": {Description: "name of the kube context of the cluster, defaults to the ClusterName"},
+	"ClusterListConfiguration.KubeconfigPath": {
+		Description: "path of the kubeconfig the kube context is read from, takes precedence over --kubeconfig, KUBECONFIG and ~/.kube/config",
+	},
+	"ClusterListConfiguration.AwsRegion":  {Description: "AWS region of the cluster", Pattern: awsRegionPattern},
+	"ClusterListConfiguration.AwsAccount": {Description: "AWS profile AWS calls against the cluster are run with"},
+	"ClusterListConfiguration.Components": {Description: "k8s objects the components are running as in the cluster"},
+	"ClusterListConfiguration.ComponentVersions": {
+		Description: "versions of the components overriding the components key for the cluster",
+	},
+	"ClusterListConfiguration.Labels": {Description: "labels the cluster can be selected with, for example env: staging"},
+	"GroupConfiguration.Selector":     {Description: "selector the clusters of the group match, for example env=staging"},
+	"GroupConfiguration.Clusters":     {Description: "names of the clusters of the group"},
+	"K8sObject.ObjectType":            {Enum: ObjectTypes},
+}
+
+// Schema returns the JSON Schema of the config file, which is generated from the mapstructure tags of Configurations,
+// ClusterListConfiguration and K8sObject. It describes config files of the CurrentVersion, the ones of the clusters.d
+// directory aren't described by it as they only have a clusterlist
+func Schema() *JSONSchema {
+	schema := schemaForType(reflect.TypeOf(Configurations{}))
+	schema.Schema = "http://json-schema.org/draft-07/schema#"
+	schema.Title = "k8s-cluster-upgrade-tool config file"
+
+	// the keys which aren't unmarshalled along with the rest of the config
+	minimumVersion, currentVersion := 1, CurrentVersion
+	schema.Properties["version"] = &JSONSchema{
+		Description: "schema version of the config file, config files of older versions can be updated with config migrate",
+		Type:        "integer",
+		Minimum:     &minimumVersion,
+		Maximum:     &currentVersion,
+	}
+	schema.Properties["componentmatrix"] = &JSONSchema{
+		Description:          "versions of the components per kubernetes minor version, taking precedence over the components key",
+		Type:                 "object",
+		PropertyNames:        &JSONSchema{Pattern: kubernetesMinorVersionRegex.String()},
+		AdditionalProperties: schemaForType(reflect.TypeOf(ComponentVersionConfigurations{})),
+	}
+	schema.AnyOf = []*JSONSchema{{Required: []string{"components"}}, {Required: []string{"componentmatrix"}}}
+
+	// the ClusterName can't have a default, and the other keys of a cluster are only required when they have no default
+	schema.Properties["defaults"].Properties["ClusterName"] = &JSONSchema{Not: &JSONSchema{}, Description: "the ClusterName can't have a default"}
+	schema.Properties["defaults"].Properties["KubeContext"] = &JSONSchema{Not: &JSONSchema{}, Description: "the KubeContext can't have a default"}
+	clusterListElement := schema.Properties["clusterlist"].Items
+	clusterListElement.Required = []string{"ClusterName"}
+	for _, key := range []string{"AwsRegion", "AwsAccount"} {
+		schema.AllOf = append(schema.AllOf, withoutDefault(key, &JSONSchema{
+			Properties: map[string]*JSONSchema{"clusterlist": {Items: &JSONSchema{Required: []string{key}}}},
+		}))
+	}
+	k8sObjectKeys := schemaKeys(reflect.TypeOf(K8sObject{}))
+	schema.AllOf = append(schema.AllOf, withoutDefault("Components", &JSONSchema{
+		Properties: map[string]*JSONSchema{"clusterlist": {Items: &JSONSchema{
+			Properties: map[string]*JSONSchema{"Components": {AdditionalProperties: &JSONSchema{Required: k8sObjectKeys}}},
+		}}},
+	}))
+	return schema
+}
+
+// MarshalSchema returns the JSON Schema of the config file as indented JSON
+func MarshalSchema() ([]byte, error) {
+	data, err := json.MarshalIndent(Schema(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// withoutDefault returns a schema applying the passed schema to config files which don't set the key in their defaults
+func withoutDefault(key string, then *JSONSchema) *JSONSchema {
+	return &JSONSchema{
+		If: &JSONSchema{Not: &JSONSchema{
+			Required:   []string{"defaults"},
+			Properties: map[string]*JSONSchema{"defaults": {Required: []string{key}}},
+		}},
+		Then: then,
+	}
+}
+
+// schemaForType returns the schema of the values of the type, structs are described by the fields which have a
+// mapstructure tag and strings can't be empty
+func schemaForType(t reflect.Type) *JSONSchema {
+	switch t.Kind() {
+	case reflect.Struct:
+		schema := &JSONSchema{Type: "object", Properties: map[string]*JSONSchema{}, AdditionalProperties: false}
+		for index := 0; index < t.NumField(); index++ {
+			field := t.Field(index)
+			key := mapstructureKey(field)
+			if key == "" {
+				continue
+			}
+			fieldSchema := schemaForType(field.Type)
+			if annotation, present := schemaAnnotations[t.Name()+"."+field.Name]; present {
+				fieldSchema.annotate(annotation)
+			}
+			schema.Properties[key] = fieldSchema
+		}
+		return schema
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Slice:
+		return &JSONSchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Int:
+		return &JSONSchema{Type: "integer"}
+	default:
+		return &JSONSchema{Type: "string", MinLength: 1}
+	}
+}
+
+// schemaKeys returns the keys of the fields of the struct type which have a mapstructure tag
+func schemaKeys(t reflect.Type) []string {
+	var keys []string
+	for index := 0; index < t.NumField(); index++ {
+		if key := mapstructureKey(t.Field(index)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// mapstructureKey returns the key of the field in the config file, which is empty for fields without a mapstructure tag
+// and the ones which aren't unmarshalled
+func mapstructureKey(field reflect.StructField) string {
+	key := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+// annotate sets the values of the annotation which are set on the schema
+func (s *JSONSchema) annotate(annotation JSONSchema) {
+	if annotation.Description != "" {
+		s.Description = annotation.Description
+	}
+	if annotation.PropertyNames != nil {
+		s.PropertyNames = annotation.PropertyNames
+	}
+	if annotation.Enum != nil {
+		s.Enum = annotation.Enum
+	}
+	if annotation.Pattern != "" {
+		s.Pattern = annotation.Pattern
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	clusterListElement := schema.Properties["clusterlist"].Items
+
+	t.Run("every key of the config types is described", func(t *testing.T) {
+		for _, key := range []string{"version", "components", "componentmatrix", "defaults", "clusterlist", "images", "eksregistries", "groups"} {
+			assert.Contains(t, schema.Properties, key)
+		}
+		for _, key := range schemaKeys(reflect.TypeOf(ClusterListConfiguration{})) {
+			assert.Contains(t, clusterListElement.Properties, key)
+		}
+		assert.Equal(t, []string{"DeploymentName", "ObjectType", "ContainerName", "Namespace"}, schemaKeys(reflect.TypeOf(K8sObject{})))
+	})
+
+	t.Run("the ObjectType is one of the object types", func(t *testing.T) {
+		k8sObject := clusterListElement.Properties["Components"].AdditionalProperties.(*JSONSchema)
+		assert.Equal(t, ObjectTypes, k8sObject.Properties["ObjectType"].Enum)
+	})
+
+	t.Run("the region pattern matches every region with a known EKS registry", func(t *testing.T) {
+		awsRegionRegex := regexp.MustCompile(clusterListElement.Properties["AwsRegion"].Pattern)
+		for region := range eksRegistryAccounts {
+			assert.True(t, awsRegionRegex.MatchString(region), region)
+		}
+		assert.False(t, awsRegionRegex.MatchString("region1"))
+		assert.False(t, awsRegionRegex.MatchString("eu-west"))
+	})
+
+	t.Run("the ClusterName is required and can't have a default", func(t *testing.T) {
+		assert.Equal(t, []string{"ClusterName"}, clusterListElement.Required)
+		assert.Equal(t, &JSONSchema{}, schema.Properties["defaults"].Properties["ClusterName"].Not)
+		assert.Equal(t, &JSONSchema{}, schema.Properties["defaults"].Properties["KubeContext"].Not)
+	})
+
+	t.Run("the schema is valid JSON", func(t *testing.T) {
+		data, err := MarshalSchema()
+		assert.Nil(t, err)
+		var decoded map[string]interface{}
+		assert.Nil(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, "http://json-schema.org/draft-07/schema#", decoded["$schema"])
+	})
+
+	t.Run("every key and region of the sample config file is described", func(t *testing.T) {
+		data, err := ioutil.ReadFile("../config.sample.yaml")
+		assert.Nil(t, err)
+		var document yaml.Node
+		assert.Nil(t, yaml.Unmarshal(data, &document))
+
+		assert.Empty(t, undescribedKeys(schema, document.Content[0], ""))
+	})
+}
+
+// undescribedKeys returns the paths of the keys of the node which the schema doesn't describe, along with the values
+// which don't match the pattern of their key
+func undescribedKeys(schema *JSONSchema, node *yaml.Node, path string) []string {
+	var undescribed []string
+	switch node.Kind {
+	case yaml.MappingNode:
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			key := node.Content[index].Value
+			keySchema, present := schema.Properties[key]
+			if !present {
+				keySchema, present = schema.AdditionalProperties.(*JSONSchema)
+			}
+			if !present || keySchema == nil {
+				undescribed = append(undescribed, path+"."+key)
+				continue
+			}
+			undescribed = append(undescribed, undescribedKeys(keySchema, node.Content[index+1], path+"."+key)...)
+		}
+	case yaml.SequenceNode:
+		for index, element := range node.Content {
+			undescribed = append(undescribed, undescribedKeys(schema.Items, element, fmt.Sprintf("%s[%d]", path, index))...)
+		}
+	case yaml.ScalarNode:
+		if schema.Pattern != "" && !regexp.MustCompile(schema.Pattern).MatchString(node.Value) {
+			undescribed = append(undescribed, path+": "+node.Value)
+		}
+	}
+	return undescribed
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selector selects clusters by their Labels, it is a comma separated list of requirements which all need to match, for
+// example "env=staging,team!=payments". The requirements are
+//   - key=value or key==value, the label is set to the value
+//   - key!=value, the label is not set to the value
+//   - key, the label is set
+//   - !key, the label is not set
+type Selector []requirement
+
+type requirement struct {
+	key      string
+	operator string
+	value    string
+}
+
+// ParseSelector returns the selector of the passed selector string
+func ParseSelector(selector string) (Selector, error) {
+	var s Selector
+	for _, field := range strings.Split(selector, ",") {
+		field = strings.TrimSpace(field)
+		var r requirement
+		switch {
+		case strings.Contains(field, "!="):
+			parts := strings.SplitN(field, "!=", 2)
+			r = requirement{strings.TrimSpace(parts[0]), "!=", strings.TrimSpace(parts[1])}
+		case strings.Contains(field, "=="):
+			parts := strings.SplitN(field, "==", 2)
+			r = requirement{strings.TrimSpace(parts[0]), "=", strings.TrimSpace(parts[1])}
+		case strings.Contains(field, "="):
+			parts := strings.SplitN(field, "=", 2)
+			r = requirement{strings.TrimSpace(parts[0]), "=", strings.TrimSpace(parts[1])}
+		case strings.HasPrefix(field, "!"):
+			r = requirement{strings.TrimSpace(strings.TrimPrefix(field, "!")), "!", ""}
+		default:
+			r = requirement{field, "", ""}
+		}
+		if r.key == "" || strings.ContainsAny(r.key, "=! ") || strings.ContainsAny(r.value, "=! ") {
+			return nil, fmt.Errorf("invalid selector %s", selector)
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// Matches returns whether the labels match every requirement of the selector
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		value, present := labels[r.key]
+		switch r.operator {
+		case "=":
+			if !present || value != r.value {
+				return false
+			}
+		case "!=":
+			if present && value == r.value {
+				return false
+			}
+		case "!":
+			if present {
+				return false
+			}
+		default:
+			if !present {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector string
+		want     Selector
+		err      error
+	}{
+		{"when the selector has one requirement", "env=staging", Selector{{"env", "=", "staging"}}, nil},
+		{"when the selector has every kind of requirement", "env==staging, team!=payments,critical,!deprecated",
+			Selector{{"env", "=", "staging"}, {"team", "!=", "payments"}, {"critical", "", ""}, {"deprecated", "!", ""}}, nil},
+		{"when the selector has a requirement without key it returns an error", "env=staging,=payments", nil,
+			errors.New("invalid selector env=staging,=payments")},
+		{"when the selector is empty it returns an error", "", nil, errors.New("invalid selector ")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSelector(tt.selector)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	labels := map[string]string{"env": "staging", "team": "payments"}
+	tests := []struct {
+		name     string
+		selector string
+		result   bool
+	}{
+		{"when the label is set to the value", "env=staging", true},
+		{"when the label is set to another value", "env=production", false},
+		{"when every requirement matches", "env=staging,team=payments", true},
+		{"when one of the requirements doesn't match", "env=staging,team=search", false},
+		{"when the label is not set to the value", "env!=production", true},
+		{"when the label is not set for a not equal requirement", "region!=eu-west-1", true},
+		{"when the label is set", "team", true},
+		{"when the label is not set", "region", false},
+		{"when the label is set for a not set requirement", "!team", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseSelector(tt.selector)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.result, selector.Matches(labels))
+		})
+	}
+}
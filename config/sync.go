@@ -0,0 +1,299 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"k8s-cluster-upgrade-tool/internal/semver"
+)
+
+// ObservedVersion is the version of a component found running in a cluster
+type ObservedVersion struct {
+	ClusterName            string
+	KubernetesMinorVersion string
+	ComponentName          string
+	Version                string
+}
+
+// VersionChange is a component version written into a config file, Key is the key it was written to, for example
+// components.coredns or clusterlist[2].ComponentVersions.coredns
+type VersionChange struct {
+	File string
+	Key  string
+	From string
+	To   string
+}
+
+// versionLocation is a key of a config file a component version is read from
+type versionLocation struct {
+	file string
+	key  string
+	// keys are the keys of the nested mappings leading to the version, starting at the root mapping of the file
+	keys []string
+	// element is the clusterlist element the keys start at instead of the root mapping
+	element *yaml.Node
+}
+
+// syncedCluster is a cluster whose version of a component is synced
+type syncedCluster struct {
+	index    int
+	cluster  ClusterListConfiguration
+	observed ObservedVersion
+}
+
+// SyncVersions returns the contents of the config files with the component versions set to the observed ones, along
+// with the changes made. Only the files which change are returned, which are the config file and the files of its
+// clusters.d directory. Versions the observed ones satisfy, including constraints, are kept.
+//
+// With perCluster, the observed versions are written as ComponentVersions overrides of the clusterlist elements.
+// Otherwise a version is written where the cluster reads it from, which is shared by other clusters unless it is an
+// override of the cluster: the ComponentVersions of the defaults, the componentmatrix row of the kubernetes version of
+// the cluster or the components key. A shared version is set to the version most of the clusters reading it run on,
+// the clusters running on another version get an override
+func (c Configurations) SyncVersions(observed []ObservedVersion, perCluster bool) (map[string][]byte, []VersionChange, error) {
+	documents := map[string]*yaml.Node{}
+	var files []string
+	readDocument := func(path string) (*yaml.Node, error) {
+		if document, present := documents[path]; present {
+			return document, nil
+		}
+		document, err := readCurrentVersionYamlFile(path)
+		if err != nil {
+			return nil, err
+		}
+		documents[path] = document
+		files = append(files, path)
+		return document, nil
+	}
+	if _, err := readDocument(c.File); err != nil {
+		return nil, nil, err
+	}
+
+	var locations []versionLocation
+	clustersByLocation := map[string][]syncedCluster{}
+	for _, observedVersion := range observed {
+		index, cluster, err := c.clusterIndex(observedVersion.ClusterName)
+		if err != nil {
+			return nil, nil, err
+		}
+		document, err := readDocument(cluster.Source.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		element := clusterElementNode(document.Content[0], cluster.Source.Index)
+		if element == nil {
+			return nil, nil, fmt.Errorf("the cluster %s could not be found in %s", cluster.ClusterName, cluster.Source.File)
+		}
+
+		synced := syncedCluster{index: index, cluster: cluster, observed: observedVersion}
+		location := c.overrideLocation(synced, element)
+		if perCluster {
+			componentVersions, err := c.GetComponentVersionsForCluster(cluster.ClusterName, observedVersion.KubernetesMinorVersion)
+			if err == nil && VersionSatisfies(observedVersion.Version, componentVersions[observedVersion.ComponentName]) {
+				continue
+			}
+		}
+		if !perCluster && nestedMappingValue(element, location.keys) == nil {
+			location = c.sharedLocation(observedVersion)
+		}
+		if _, present := clustersByLocation[location.file+":"+location.key]; !present {
+			locations = append(locations, location)
+		}
+		clustersByLocation[location.file+":"+location.key] = append(clustersByLocation[location.file+":"+location.key], synced)
+	}
+
+	var changes []VersionChange
+	for _, location := range locations {
+		clusters := clustersByLocation[location.file+":"+location.key]
+		root := documents[location.file].Content[0]
+		if location.element != nil {
+			root = location.element
+		}
+		current := ""
+		if node := nestedMappingValue(root, location.keys); node != nil {
+			current = node.Value
+		}
+
+		version := syncedVersion(current, clusters)
+		if version != current {
+			setNestedMappingValue(root, location.keys, version)
+			changes = append(changes, VersionChange{File: location.file, Key: location.key, From: current, To: version})
+		}
+		// the clusters which don't run on the shared version get an override
+		for _, synced := range clusters {
+			if location.element != nil || VersionSatisfies(synced.observed.Version, version) {
+				continue
+			}
+			element := clusterElementNode(documents[synced.cluster.Source.File].Content[0], synced.cluster.Source.Index)
+			override := c.overrideLocation(synced, element)
+			setNestedMappingValue(element, override.keys, synced.observed.Version)
+			changes = append(changes, VersionChange{File: override.file, Key: override.key, To: synced.observed.Version})
+		}
+	}
+
+	syncedFiles := map[string][]byte{}
+	for _, file := range files {
+		changed := false
+		for _, change := range changes {
+			changed = changed || change.File == file
+		}
+		if !changed {
+			continue
+		}
+		data, err := marshalYaml(documents[file])
+		if err != nil {
+			return nil, nil, err
+		}
+		syncedFiles[file] = data
+	}
+	return syncedFiles, changes, nil
+}
+
+// readCurrentVersionYamlFile returns the document node of the config file at the passed path, config files of older
+// schema versions need to be migrated before they are rewritten
+func readCurrentVersionYamlFile(path string) (*yaml.Node, error) {
+	document, err := readYamlFile(path)
+	if err != nil {
+		return nil, err
+	}
+	version, err := detectVersion(document.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	if version != CurrentVersion {
+		return nil, fmt.Errorf("the config file %s is of the schema version %d, please migrate it with config migrate first", path, version)
+	}
+	return document, nil
+}
+
+// clusterIndex returns the index of the cluster in the clusterlist along with the cluster
+func (c Configurations) clusterIndex(clusterName string) (int, ClusterListConfiguration, error) {
+	for index, cluster := range c.ClusterList {
+		if cluster.ClusterName == clusterName {
+			if cluster.Source.File == "" {
+				cluster.Source.File = c.File
+			}
+			return index, cluster, nil
+		}
+	}
+	return 0, ClusterListConfiguration{}, fmt.Errorf("the cluster %s could not be found in the clusterlist", clusterName)
+}
+
+// overrideLocation returns the location of the ComponentVersions override of the component in the clusterlist element
+func (c Configurations) overrideLocation(synced syncedCluster, element *yaml.Node) versionLocation {
+	componentName := synced.observed.ComponentName
+	return versionLocation{
+		file:    synced.cluster.Source.File,
+		key:     fmt.Sprintf("%s.ComponentVersions.%s", c.clusterPath(synced.index, synced.cluster), componentName),
+		keys:    []string{"ComponentVersions", componentName},
+		element: element,
+	}
+}
+
+// sharedLocation returns the location the version of the component is read from for clusters without an override
+func (c Configurations) sharedLocation(observed ObservedVersion) versionLocation {
+	componentName := observed.ComponentName
+	if _, present := c.Defaults.ComponentVersions[componentName]; present {
+		return versionLocation{
+			file: c.File,
+			key:  "defaults.ComponentVersions." + componentName,
+			keys: []string{"defaults", "ComponentVersions", componentName},
+		}
+	}
+	if _, present := c.ComponentMatrix[observed.KubernetesMinorVersion][componentName]; present {
+		return versionLocation{
+			file: c.File,
+			key:  fmt.Sprintf("componentmatrix[%q].%s", observed.KubernetesMinorVersion, componentName),
+			keys: []string{"componentmatrix", observed.KubernetesMinorVersion, componentName},
+		}
+	}
+	return versionLocation{file: c.File, key: "components." + componentName, keys: []string{"components", componentName}}
+}
+
+// syncedVersion returns the version to set at a location read by the passed clusters, which is the current version
+// when the versions of most of the clusters satisfy it, or else the version most of the clusters run on
+func syncedVersion(current string, clusters []syncedCluster) string {
+	satisfying := 0
+	counts := map[string]int{}
+	mostObserved := ""
+	for _, synced := range clusters {
+		if VersionSatisfies(synced.observed.Version, current) {
+			satisfying++
+		}
+		counts[synced.observed.Version]++
+		if counts[synced.observed.Version] > counts[mostObserved] {
+			mostObserved = synced.observed.Version
+		}
+	}
+	if current != "" && satisfying >= counts[mostObserved] {
+		return current
+	}
+	return mostObserved
+}
+
+// VersionSatisfies returns whether the version satisfies the desired version, which is either a version or a constraint.
+// A desired version which isn't configured is never satisfied
+func VersionSatisfies(version, desiredVersion string) bool {
+	if desiredVersion == "" {
+		return false
+	}
+	if version == desiredVersion {
+		return true
+	}
+	result, err := semver.Check(version, desiredVersion)
+	return err == nil && result == semver.Satisfies
+}
+
+// clusterElementNode returns the mapping node of the element at the passed index of the clusterlist of the root mapping
+func clusterElementNode(root *yaml.Node, index int) *yaml.Node {
+	clusterList := mappingValue(root, "clusterlist")
+	if clusterList == nil || clusterList.Kind != yaml.SequenceNode || index >= len(clusterList.Content) ||
+		clusterList.Content[index].Kind != yaml.MappingNode {
+		return nil
+	}
+	return clusterList.Content[index]
+}
+
+// nestedMappingValue returns the value at the keys of the nested mappings, or nil when any of the keys is not present
+func nestedMappingValue(mapping *yaml.Node, keys []string) *yaml.Node {
+	for _, key := range keys {
+		if mapping == nil {
+			return nil
+		}
+		mapping = mappingValue(mapping, fileKey(mapping, key))
+	}
+	return mapping
+}
+
+// fileKey returns the key as it is spelled in the mapping node when the mapping has a key only differing in case, as the
+// component names are read lowercased, or else the passed key
+func fileKey(mapping *yaml.Node, key string) string {
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if strings.EqualFold(mapping.Content[index].Value, key) {
+			return mapping.Content[index].Value
+		}
+	}
+	return key
+}
+
+// setNestedMappingValue sets the scalar value at the keys of the nested mappings, adding the mappings which are not
+// present. The style and the comments of a replaced value are kept
+func setNestedMappingValue(mapping *yaml.Node, keys []string, value string) {
+	for _, key := range keys[:len(keys)-1] {
+		key = fileKey(mapping, key)
+		next := mappingValue(mapping, key)
+		if next == nil || next.Kind != yaml.MappingNode {
+			next = setMappingValue(mapping, key, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+		}
+		mapping = next
+	}
+	key := fileKey(mapping, keys[len(keys)-1])
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if current := mappingValue(mapping, key); current != nil {
+		valueNode.Style = current.Style
+		valueNode.HeadComment, valueNode.LineComment, valueNode.FootComment = current.HeadComment, current.LineComment, current.FootComment
+	}
+	setMappingValue(mapping, key, valueNode)
+}
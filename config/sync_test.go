@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigurations_SyncVersions(t *testing.T) {
+	clusterYaml := func(clusterName, extra string) string {
+		return "- ClusterName: " + clusterName + "\n  AwsRegion: region1\n  AwsAccount: account1\n  Components:\n    coredns:\n      ObjectType: deployment\n      DeploymentName: coredns\n      ContainerName: coredns\n      Namespace: kube-system\n" + extra
+	}
+	load := func(t *testing.T, configYaml string, clustersFiles map[string]string) Configurations {
+		dir := t.TempDir()
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "config.yaml"), []byte(configYaml), 0644))
+		if len(clustersFiles) != 0 {
+			assert.Nil(t, os.Mkdir(filepath.Join(dir, "clusters.d"), 0755))
+		}
+		for name, data := range clustersFiles {
+			assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "clusters.d", name), []byte(data), 0644))
+		}
+		configuration, err := Loader{}.Load(filepath.Join(dir, "config.yaml"))
+		assert.Nil(t, err)
+		return configuration
+	}
+	observed := func(clusterName, version string) ObservedVersion {
+		return ObservedVersion{ClusterName: clusterName, ComponentName: "coredns", Version: version}
+	}
+
+	t.Run("when the clusters run on the configured versions nothing is changed", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \">=1.8 <1.9\"\nclusterlist:\n"+clusterYaml("cluster1", "")+clusterYaml("cluster2", ""), nil)
+
+		files, changes, err := configuration.SyncVersions([]ObservedVersion{observed("cluster1", "v1.8.4"), observed("cluster2", "v1.8.7")}, false)
+
+		assert.Nil(t, err)
+		assert.Empty(t, files)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("when the clusters run on another version the shared version is set and comments are kept", func(t *testing.T) {
+		configuration := load(t, "# versions of every cluster\ncomponents:\n  coredns: \"v1.8.4\" # coredns\nclusterlist:\n"+clusterYaml("cluster1", ""), nil)
+
+		files, changes, err := configuration.SyncVersions([]ObservedVersion{observed("cluster1", "v1.9.3")}, false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []VersionChange{{File: configuration.File, Key: "components.coredns", From: "v1.8.4", To: "v1.9.3"}}, changes)
+		assert.Contains(t, string(files[configuration.File]), "# versions of every cluster\ncomponents:\n  coredns: \"v1.9.3\" # coredns\n")
+	})
+
+	t.Run("when the clusters run on different versions the shared version is the one most of them run on", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\nclusterlist:\n"+clusterYaml("cluster1", "")+
+			clusterYaml("cluster2", "")+clusterYaml("cluster3", ""), nil)
+
+		files, changes, err := configuration.SyncVersions([]ObservedVersion{
+			observed("cluster1", "v1.9.3"), observed("cluster2", "v1.8.7"), observed("cluster3", "v1.9.3"),
+		}, false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []VersionChange{
+			{File: configuration.File, Key: "components.coredns", From: "v1.8.4", To: "v1.9.3"},
+			{File: configuration.File, Key: "clusterlist[1].ComponentVersions.coredns", To: "v1.8.7"},
+		}, changes)
+		synced, err := Read(FileMetadataForPath(writeTempFile(t, files[configuration.File])))
+		assert.Nil(t, err)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.9.3"}, synced.Components)
+		assert.Equal(t, ComponentVersionConfigurations{"coredns": "v1.8.7"}, synced.ClusterList[1].ComponentVersions)
+	})
+
+	t.Run("when most of the clusters run on the shared version it is kept", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\nclusterlist:\n"+clusterYaml("cluster1", "")+
+			clusterYaml("cluster2", ""), nil)
+
+		_, changes, err := configuration.SyncVersions([]ObservedVersion{observed("cluster1", "v1.8.4"), observed("cluster2", "v1.9.3")}, false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []VersionChange{{File: configuration.File, Key: "clusterlist[1].ComponentVersions.coredns", To: "v1.9.3"}}, changes)
+	})
+
+	t.Run("when a cluster has an override the override is set", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\nclusterlist:\n"+
+			clusterYaml("cluster1", "  ComponentVersions:\n    coredns: v1.8.7\n"), nil)
+
+		_, changes, err := configuration.SyncVersions([]ObservedVersion{observed("cluster1", "v1.9.3")}, false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []VersionChange{{File: configuration.File, Key: "clusterlist[0].ComponentVersions.coredns", From: "v1.8.7", To: "v1.9.3"}}, changes)
+	})
+
+	t.Run("when the override of a cluster is spelled in another case the override is set", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\nclusterlist:\n"+
+			clusterYaml("cluster1", "  ComponentVersions:\n    CoreDNS: v1.8.7\n"), nil)
+
+		files, _, err := configuration.SyncVersions([]ObservedVersion{observed("cluster1", "v1.9.3")}, false)
+
+		assert.Nil(t, err)
+		assert.Contains(t, string(files[configuration.File]), "ComponentVersions:\n      CoreDNS: v1.9.3\n")
+		assert.NotContains(t, string(files[configuration.File]), "coredns: v1.9.3")
+	})
+
+	t.Run("when the versions are set per cluster only the clusters not on the configured version get an override", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\nclusterlist:\n"+clusterYaml("cluster1", "")+
+			clusterYaml("cluster2", ""), nil)
+
+		_, changes, err := configuration.SyncVersions([]ObservedVersion{observed("cluster1", "v1.9.3"), observed("cluster2", "v1.8.4")}, true)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []VersionChange{{File: configuration.File, Key: "clusterlist[0].ComponentVersions.coredns", To: "v1.9.3"}}, changes)
+	})
+
+	t.Run("when the version is set in the defaults the defaults are set", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\ndefaults:\n  ComponentVersions:\n    coredns: v1.8.7\nclusterlist:\n"+
+			clusterYaml("cluster1", ""), nil)
+
+		_, changes, err := configuration.SyncVersions([]ObservedVersion{observed("cluster1", "v1.9.3")}, false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []VersionChange{{File: configuration.File, Key: "defaults.ComponentVersions.coredns", From: "v1.8.7", To: "v1.9.3"}}, changes)
+	})
+
+	t.Run("when the config file has a componentmatrix the row of the kubernetes version of the cluster is set", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\ncomponentmatrix:\n  \"1.27\":\n    coredns: v1.10.1\nclusterlist:\n"+
+			clusterYaml("cluster1", ""), nil)
+		observedVersion := observed("cluster1", "v1.10.1-eksbuild.2")
+		observedVersion.KubernetesMinorVersion = "1.27"
+
+		_, changes, err := configuration.SyncVersions([]ObservedVersion{observedVersion}, false)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []VersionChange{{File: configuration.File, Key: `componentmatrix["1.27"].coredns`, From: "v1.10.1", To: "v1.10.1-eksbuild.2"}}, changes)
+	})
+
+	t.Run("when a cluster is read from the clusters directory its override is set in its file", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\nclusterlist:\n"+clusterYaml("cluster1", ""),
+			map[string]string{"prod.yaml": "clusterlist:\n" + clusterYaml("cluster2", "")})
+		clustersFile := filepath.Join(filepath.Dir(configuration.File), "clusters.d", "prod.yaml")
+
+		files, changes, err := configuration.SyncVersions([]ObservedVersion{observed("cluster1", "v1.8.4"), observed("cluster2", "v1.9.3")}, true)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []VersionChange{{File: clustersFile, Key: "clusters.d/prod.yaml:clusterlist[0].ComponentVersions.coredns", To: "v1.9.3"}}, changes)
+		assert.Contains(t, string(files[clustersFile]), "  ComponentVersions:\n      coredns: v1.9.3\n")
+		assert.NotContains(t, files, configuration.File)
+	})
+
+	t.Run("when the cluster is not in the clusterlist an error is returned", func(t *testing.T) {
+		configuration := load(t, "components:\n  coredns: \"v1.8.4\"\nclusterlist:\n"+clusterYaml("cluster1", ""), nil)
+
+		_, _, err := configuration.SyncVersions([]ObservedVersion{observed("cluster9", "v1.8.4")}, false)
+
+		assert.Equal(t, errors.New("the cluster cluster9 could not be found in the clusterlist"), err)
+	})
+}
+
+func writeTempFile(t *testing.T, data []byte) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.Nil(t, ioutil.WriteFile(path, data, 0644))
+	return path
+}
+
+func TestVersionSatisfies(t *testing.T) {
+	tests := []struct {
+		name           string
+		version        string
+		desiredVersion string
+		want           bool
+	}{
+		{"when the version is the desired version it is satisfied", "v1.8.4-eksbuild.1", "v1.8.4-eksbuild.1", true},
+		{"when the version satisfies the constraint it is satisfied", "v1.10.1-eksbuild.2", ">=1.10.1 <1.11", true},
+		{"when the version doesn't satisfy the constraint it isn't satisfied", "v1.11.0", ">=1.10.1 <1.11", false},
+		{"when the version isn't semantic and equals the desired version it is satisfied", "latest", "latest", true},
+		{"when no desired version is configured it isn't satisfied", "v1.8.4", "", false},
+		{"when neither a version nor a desired version is set it isn't satisfied", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, VersionSatisfies(tt.version, tt.desiredVersion))
+		})
+	}
+}
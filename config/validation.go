@@ -0,0 +1,311 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"k8s-cluster-upgrade-tool/internal/semver"
+)
+
+var imagePlaceholderRegex = regexp.MustCompile(`\{[^}]*\}`)
+
+// ValidationError is a problem found in the config, Path points to the key in the config the problem was found at,
+// for example clusterlist[3].Components.coredns.Namespace. The keys of clusters read from the clusters.d directory are
+// prefixed with the file they were read from, for example clusters.d/prod.yaml:clusterlist[0].AwsRegion
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (v ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// ValidationErrors are all the problems found in the config
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	problems := make([]string, 0, len(v))
+	for _, validationError := range v {
+		problems = append(problems, validationError.Error())
+	}
+	return fmt.Sprintf("the config has %d problem(s):\n%s", len(v), strings.Join(problems, "\n"))
+}
+
+// Validate returns every problem found in the config, it returns an empty list when the config is valid
+func (c Configurations) Validate() ValidationErrors {
+	validationErrors := c.ValidateComponentVersionConfigurations()
+	validationErrors = append(validationErrors, c.ValidateDefaults()...)
+	validationErrors = append(validationErrors, c.ValidateClusterListConfiguration()...)
+	validationErrors = append(validationErrors, c.ValidateImages()...)
+	return append(validationErrors, c.ValidateGroups()...)
+}
+
+// ValidateImages returns the problems found with the image repositories under the images key and the registries under
+// the eksregistries key
+func (c Configurations) ValidateImages() ValidationErrors {
+	validationErrors := ValidationErrors{}
+	componentNames := map[string]bool{}
+	for _, componentName := range c.componentNames() {
+		componentNames[componentName] = true
+	}
+	imageComponentNames := make([]string, 0, len(c.Images))
+	for componentName := range c.Images {
+		imageComponentNames = append(imageComponentNames, componentName)
+	}
+	sort.Strings(imageComponentNames)
+	for _, componentName := range imageComponentNames {
+		path := "images." + componentName
+		image := c.Images[componentName]
+		if !componentNames[componentName] {
+			validationErrors = append(validationErrors, ValidationError{path, "not a component configured with a version or an object"})
+		}
+		validationErrors = append(validationErrors, validateNotEmpty(path, image)...)
+		if strings.Contains(image, "@") || strings.Contains(image[strings.LastIndex(image, "/")+1:], ":") {
+			validationErrors = append(validationErrors, ValidationError{path, "has a tag or digest, only the image repository is set as the tag is the component version"})
+		}
+		placeholders := strings.NewReplacer("{region}", "", "{account}", "", "{eks-registry}", "")
+		if placeholder := imagePlaceholderRegex.FindString(placeholders.Replace(image)); placeholder != "" {
+			validationErrors = append(validationErrors, ValidationError{path, fmt.Sprintf("unknown placeholder %s, the placeholders are {region}, {account} and {eks-registry}", placeholder)})
+		}
+		if strings.Contains(image, "{eks-registry}") {
+			for index, cluster := range c.ClusterList {
+				if _, err := c.GetEksRegistry(cluster.AwsRegion); err != nil && cluster.AwsRegion != "" {
+					validationErrors = append(validationErrors, ValidationError{c.clusterPath(index, cluster) + ".AwsRegion",
+						fmt.Sprintf("no EKS registry is known for the region %s, which %s uses for {eks-registry}, please configure it under the eksregistries key", cluster.AwsRegion, path)})
+				}
+			}
+		}
+	}
+
+	eksRegions := make([]string, 0, len(c.EksRegistries))
+	for region := range c.EksRegistries {
+		eksRegions = append(eksRegions, region)
+	}
+	sort.Strings(eksRegions)
+	for _, region := range eksRegions {
+		path := "eksregistries." + region
+		registry := c.EksRegistries[region]
+		validationErrors = append(validationErrors, validateNotEmpty(path, registry)...)
+		if strings.Contains(registry, "/") {
+			validationErrors = append(validationErrors, ValidationError{path, "has a path, only the registry host is set, for example 602401143452.dkr.ecr.eu-west-1.amazonaws.com"})
+		}
+	}
+	return validationErrors
+}
+
+// ValidateGroups returns the problems found with the groups under the groups key
+func (c Configurations) ValidateGroups() ValidationErrors {
+	validationErrors := ValidationErrors{}
+	groupNames := make([]string, 0, len(c.Groups))
+	for groupName := range c.Groups {
+		groupNames = append(groupNames, groupName)
+	}
+	sort.Strings(groupNames)
+
+	for _, groupName := range groupNames {
+		path := "groups." + groupName
+		group := c.Groups[groupName]
+		if group.Selector == "" && len(group.Clusters) == 0 {
+			validationErrors = append(validationErrors, ValidationError{path, "empty, neither a Selector nor Clusters are set"})
+		}
+		if group.Selector != "" {
+			if _, err := ParseSelector(group.Selector); err != nil {
+				validationErrors = append(validationErrors, ValidationError{path + ".Selector", err.Error()})
+			}
+		}
+		for index, clusterName := range group.Clusters {
+			if !c.IsClusterNameValid(clusterName) {
+				validationErrors = append(validationErrors, ValidationError{
+					fmt.Sprintf("%s.Clusters[%d]", path, index),
+					fmt.Sprintf("%q is not a ClusterName of the clusterlist", clusterName),
+				})
+			}
+		}
+	}
+	return validationErrors
+}
+
+// ValidateComponentVersionConfigurations returns the problems found with the component versions under the components
+// and componentmatrix keys
+func (c Configurations) ValidateComponentVersionConfigurations() ValidationErrors {
+	validationErrors := ValidationErrors{}
+	if len(c.Components) == 0 && len(c.ComponentMatrix) == 0 {
+		validationErrors = append(validationErrors, ValidationError{"components", "no component versions set, neither under components nor under componentmatrix"})
+	}
+	validationErrors = append(validationErrors, validateComponentVersions("components", c.Components)...)
+
+	kubernetesMinorVersions := make([]string, 0, len(c.ComponentMatrix))
+	for kubernetesMinorVersion := range c.ComponentMatrix {
+		kubernetesMinorVersions = append(kubernetesMinorVersions, kubernetesMinorVersion)
+	}
+	sort.Strings(kubernetesMinorVersions)
+	for _, kubernetesMinorVersion := range kubernetesMinorVersions {
+		path := fmt.Sprintf("componentmatrix[%q]", kubernetesMinorVersion)
+		if !kubernetesMinorVersionRegex.MatchString(kubernetesMinorVersion) {
+			validationErrors = append(validationErrors, ValidationError{path, "not a kubernetes minor version of the format 1.27"})
+		}
+		validationErrors = append(validationErrors, validateComponentVersions(path, c.ComponentMatrix[kubernetesMinorVersion])...)
+	}
+	return validationErrors
+}
+
+// validateComponentMatrixKeys returns the problems found with the keys of the componentmatrix rows of the config file,
+// which have to be strings. YAML reads an unquoted 1.30 as the number 1.3, which would be taken for the row of another
+// kubernetes version
+func validateComponentMatrixKeys(data []byte) ValidationErrors {
+	validationErrors := ValidationErrors{}
+	var document yaml.Node
+	if yaml.Unmarshal(data, &document) != nil || document.Kind != yaml.DocumentNode {
+		return validationErrors
+	}
+	componentMatrix := mappingValue(document.Content[0], "componentmatrix")
+	if componentMatrix == nil || componentMatrix.Kind != yaml.MappingNode {
+		return validationErrors
+	}
+	for index := 0; index+1 < len(componentMatrix.Content); index += 2 {
+		key := componentMatrix.Content[index]
+		if key.ShortTag() != "!!str" {
+			validationErrors = append(validationErrors, ValidationError{fmt.Sprintf("componentmatrix[%q]", key.Value),
+				fmt.Sprintf("not a string, YAML reads %s as a number, please quote the kubernetes version like \"%s\"", key.Value, key.Value)})
+		}
+	}
+	return validationErrors
+}
+
+// ValidateDefaults returns the problems found with the defaults, the values of the defaults are validated along with
+// the clusters they are merged into
+func (c Configurations) ValidateDefaults() ValidationErrors {
+	validationErrors := ValidationErrors{}
+	if c.Defaults.ClusterName != "" {
+		validationErrors = append(validationErrors, ValidationError{"defaults.ClusterName", "set, the ClusterName can't have a default"})
+	}
+	if c.Defaults.KubeContext != "" {
+		validationErrors = append(validationErrors, ValidationError{"defaults.KubeContext", "set, the KubeContext can't have a default"})
+	}
+	return validationErrors
+}
+
+// ValidateClusterListConfiguration returns the problems found with the elements of the clusterlist
+func (c Configurations) ValidateClusterListConfiguration() ValidationErrors {
+	validationErrors := ValidationErrors{}
+	validationErrors = append(validationErrors, c.validateClusterNamesAreUnique()...)
+
+	allComponentVersions := c.allComponentVersions()
+	for index, cluster := range c.ClusterList {
+		path := c.clusterPath(index, cluster)
+		validationErrors = append(validationErrors, validateNotEmpty(path+".ClusterName", cluster.ClusterName)...)
+		validationErrors = append(validationErrors, validateNotEmpty(path+".AwsRegion", cluster.AwsRegion)...)
+		validationErrors = append(validationErrors, validateNotEmpty(path+".AwsAccount", cluster.AwsAccount)...)
+		validationErrors = append(validationErrors, validateComponentVersions(path+".ComponentVersions", cluster.ComponentVersions)...)
+
+		// every component which has a version set for the cluster needs to be present in the cluster, and every
+		// object which is configured needs to be complete
+		for _, componentName := range allComponentVersions.MergedWith(cluster.ComponentVersions).Names() {
+			if _, present := cluster.Components[componentName]; !present {
+				validationErrors = append(validationErrors, ValidationError{
+					fmt.Sprintf("%s.Components.%s", path, componentName),
+					"missing, the component has a version set but no object configured for the cluster",
+				})
+			}
+		}
+
+		componentNames := make([]string, 0, len(cluster.Components))
+		for componentName := range cluster.Components {
+			componentNames = append(componentNames, componentName)
+		}
+		sort.Strings(componentNames)
+		for _, componentName := range componentNames {
+			objectPath := fmt.Sprintf("%s.Components.%s", path, componentName)
+			validationErrors = append(validationErrors, validateK8sObject(objectPath, cluster.Components[componentName])...)
+		}
+	}
+	return validationErrors
+}
+
+func (c Configurations) validateClusterNamesAreUnique() ValidationErrors {
+	validationErrors := ValidationErrors{}
+	clusterNameIndexes := map[string][]string{}
+	var clusterNames []string
+	for index, cluster := range c.ClusterList {
+		if cluster.ClusterName == "" {
+			continue
+		}
+		if _, present := clusterNameIndexes[cluster.ClusterName]; !present {
+			clusterNames = append(clusterNames, cluster.ClusterName)
+		}
+		clusterNameIndexes[cluster.ClusterName] = append(clusterNameIndexes[cluster.ClusterName],
+			strings.TrimPrefix(c.clusterPath(index, cluster), "clusterlist"))
+	}
+
+	for _, clusterName := range clusterNames {
+		indexes := clusterNameIndexes[clusterName]
+		if len(indexes) > 1 {
+			validationErrors = append(validationErrors, ValidationError{
+				"clusterlist",
+				fmt.Sprintf("duplicate ClusterName %q at %s and %s", clusterName,
+					strings.Join(indexes[:len(indexes)-1], ", "), indexes[len(indexes)-1]),
+			})
+		}
+	}
+	return validationErrors
+}
+
+// clusterPath returns the path of the clusterlist element at the passed index, which is prefixed with the file the
+// cluster was read from when it wasn't read from the config file
+func (c Configurations) clusterPath(index int, cluster ClusterListConfiguration) string {
+	if cluster.Source.File == "" || cluster.Source.File == c.File {
+		return fmt.Sprintf("clusterlist[%d]", index)
+	}
+	return fmt.Sprintf("%s:clusterlist[%d]", c.RelativePath(cluster.Source.File), cluster.Source.Index)
+}
+
+func validateComponentVersions(path string, componentVersions ComponentVersionConfigurations) ValidationErrors {
+	validationErrors := ValidationErrors{}
+	for _, componentName := range componentVersions.Names() {
+		componentVersion := componentVersions[componentName]
+		validationErrors = append(validationErrors, validateNotEmpty(path+"."+componentName, componentVersion)...)
+		if semver.IsConstraint(componentVersion) {
+			if _, err := semver.ParseConstraint(componentVersion); err != nil {
+				validationErrors = append(validationErrors, ValidationError{path + "." + componentName, err.Error()})
+			}
+		}
+	}
+	return validationErrors
+}
+
+func validateK8sObject(path string, k8sObject K8sObject) ValidationErrors {
+	validationErrors := ValidationErrors{}
+	validationErrors = append(validationErrors, validateNotEmpty(path+".DeploymentName", k8sObject.DeploymentName)...)
+	validationErrors = append(validationErrors, validateNotEmpty(path+".ObjectType", k8sObject.ObjectType)...)
+	validationErrors = append(validationErrors, validateNotEmpty(path+".ContainerName", k8sObject.ContainerName)...)
+	validationErrors = append(validationErrors, validateNotEmpty(path+".Namespace", k8sObject.Namespace)...)
+
+	if k8sObject.ObjectType != "" && !isObjectType(k8sObject.ObjectType) {
+		validationErrors = append(validationErrors, ValidationError{
+			path + ".ObjectType",
+			fmt.Sprintf("%q is not one of %s", k8sObject.ObjectType, strings.Join(ObjectTypes, ", ")),
+		})
+	}
+	return validationErrors
+}
+
+func validateNotEmpty(path, value string) ValidationErrors {
+	if value == "" {
+		return ValidationErrors{{path, "empty"}}
+	}
+	return nil
+}
+
+func isObjectType(objectType string) bool {
+	for _, validObjectType := range ObjectTypes {
+		if objectType == validObjectType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validCluster(clusterName string) ClusterListConfiguration {
+	return ClusterListConfiguration{
+		ClusterName: clusterName,
+		AwsRegion:   "region",
+		AwsAccount:  "account",
+		Components: map[string]K8sObject{
+			"coredns": {DeploymentName: "coredns", ObjectType: "deployment", ContainerName: "coredns", Namespace: "kube-system"},
+		},
+	}
+}
+
+func TestConfigurations_Validate(t *testing.T) {
+	duplicateClusters := []ClusterListConfiguration{
+		validCluster("cluster0"), validCluster("cluster1"), validCluster("prod-1"), validCluster("cluster3"),
+		validCluster("cluster4"), validCluster("cluster5"), validCluster("cluster6"), validCluster("prod-1"),
+	}
+	incompleteCluster := validCluster("cluster1")
+	incompleteCluster.Components = map[string]K8sObject{
+		"coredns": {DeploymentName: "coredns", ObjectType: "deployment", ContainerName: "coredns"},
+	}
+
+	tests := []struct {
+		name          string
+		configuration Configurations
+		want          ValidationErrors
+	}{
+		{"when the config is valid it returns no problems",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: []ClusterListConfiguration{validCluster("cluster1"), validCluster("cluster2")},
+			},
+			ValidationErrors{},
+		},
+		{"when the cluster names are duplicated it returns the indexes of the duplicates",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: append(duplicateClusters, validCluster("cluster8"), validCluster("cluster1")),
+			},
+			ValidationErrors{
+				{"clusterlist", `duplicate ClusterName "cluster1" at [1] and [9]`},
+				{"clusterlist", `duplicate ClusterName "prod-1" at [2] and [7]`},
+			},
+		},
+		{"when an attribute of an object is missing it returns the path of the attribute",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: []ClusterListConfiguration{validCluster("cluster0"), incompleteCluster},
+			},
+			ValidationErrors{{"clusterlist[1].Components.coredns.Namespace", "empty"}},
+		},
+		{"when a component version is an invalid constraint it returns the problem with the constraint",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": ">=1.10.1 <1.11", "kube-proxy": "~latest"},
+				ClusterList: []ClusterListConfiguration{},
+			},
+			ValidationErrors{{"components.kube-proxy", "invalid constraint ~latest, invalid version latest"}},
+		},
+		{"when a group is invalid it returns the problems of the group",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: []ClusterListConfiguration{validCluster("cluster1")},
+				Groups: map[string]GroupConfiguration{
+					"canary":   {Selector: "env=staging", Clusters: []string{"cluster1", "cluster2"}},
+					"empty":    {},
+					"payments": {Selector: "team==payments,=x"},
+				},
+			},
+			ValidationErrors{
+				{"groups.canary.Clusters[1]", `"cluster2" is not a ClusterName of the clusterlist`},
+				{"groups.empty", "empty, neither a Selector nor Clusters are set"},
+				{"groups.payments.Selector", "invalid selector team==payments,=x"},
+			},
+		},
+		{"when an image repository is invalid it returns the problems of the image repository",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: []ClusterListConfiguration{validCluster("cluster1")},
+				Images: map[string]string{
+					"coredns":    "{account}.dkr.ecr.{region}.amazonaws.com/eks/coredns:v1.8.4",
+					"kube-proxy": "registry.example.com:5000/{cluster}/kube-proxy",
+				},
+			},
+			ValidationErrors{
+				{"images.coredns", "has a tag or digest, only the image repository is set as the tag is the component version"},
+				{"images.kube-proxy", "not a component configured with a version or an object"},
+				{"images.kube-proxy", "unknown placeholder {cluster}, the placeholders are {region}, {account} and {eks-registry}"},
+			},
+		},
+		{"when an image repository uses the EKS registry of a region which is unknown it returns the cluster of the region",
+			Configurations{
+				Components:    ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList:   []ClusterListConfiguration{validCluster("cluster1")},
+				Images:        map[string]string{"coredns": "{eks-registry}/eks/coredns"},
+				EksRegistries: map[string]string{"eu-west-1": "602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks"},
+			},
+			ValidationErrors{
+				{"clusterlist[0].AwsRegion", "no EKS registry is known for the region region, which images.coredns uses for {eks-registry}, please configure it under the eksregistries key"},
+				{"eksregistries.eu-west-1", "has a path, only the registry host is set, for example 602401143452.dkr.ecr.eu-west-1.amazonaws.com"},
+			},
+		},
+		{"when the defaults set a ClusterName it returns an error",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				Defaults:    ClusterListConfiguration{ClusterName: "cluster1"},
+				ClusterList: []ClusterListConfiguration{validCluster("cluster1")},
+			},
+			ValidationErrors{{"defaults.ClusterName", "set, the ClusterName can't have a default"}},
+		},
+		{"when the defaults set a KubeContext it returns an error",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				Defaults:    ClusterListConfiguration{KubeContext: "context1"},
+				ClusterList: []ClusterListConfiguration{validCluster("cluster1")},
+			},
+			ValidationErrors{{"defaults.KubeContext", "set, the KubeContext can't have a default"}},
+		},
+		{"when the object type of an object is not valid",
+			Configurations{
+				Components: ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: []ClusterListConfiguration{{
+					ClusterName: "cluster1", AwsRegion: "region", AwsAccount: "account",
+					Components: map[string]K8sObject{
+						"coredns": {DeploymentName: "deployment", ObjectType: "coredns", ContainerName: "coredns", Namespace: "kube-system"},
+					},
+				}},
+			},
+			ValidationErrors{{"clusterlist[0].Components.coredns.ObjectType", `"coredns" is not one of daemonset, deployment, statefulset`}},
+		},
+		{"when every attribute of a cluster is missing it returns every problem",
+			Configurations{
+				Components:  ComponentVersionConfigurations{"coredns": "v1.8.4"},
+				ClusterList: []ClusterListConfiguration{{Components: map[string]K8sObject{"coredns": {}}}},
+			},
+			ValidationErrors{
+				{"clusterlist[0].ClusterName", "empty"},
+				{"clusterlist[0].AwsRegion", "empty"},
+				{"clusterlist[0].AwsAccount", "empty"},
+				{"clusterlist[0].Components.coredns.DeploymentName", "empty"},
+				{"clusterlist[0].Components.coredns.ObjectType", "empty"},
+				{"clusterlist[0].Components.coredns.ContainerName", "empty"},
+				{"clusterlist[0].Components.coredns.Namespace", "empty"},
+			},
+		},
+		{"when no component versions are set",
+			Configurations{ClusterList: []ClusterListConfiguration{validCluster("cluster1")}},
+			ValidationErrors{{"components", "no component versions set, neither under components nor under componentmatrix"}},
+		},
+		{"when a componentmatrix row is not valid",
+			Configurations{
+				ComponentMatrix: map[string]ComponentVersionConfigurations{
+					"1.27":   {"coredns": "v1.10.1-eksbuild.2"},
+					"1.2x.1": {"coredns": ""},
+				},
+				ClusterList: []ClusterListConfiguration{validCluster("cluster1")},
+			},
+			ValidationErrors{
+				{`componentmatrix["1.2x.1"]`, "not a kubernetes minor version of the format 1.27"},
+				{`componentmatrix["1.2x.1"].coredns`, "empty"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.configuration.Validate())
+		})
+	}
+}
+
+func TestValidationErrors_Error(t *testing.T) {
+	t.Run("returns every problem with its path on its own line", func(t *testing.T) {
+		validationErrors := ValidationErrors{
+			{"clusterlist[3].Components.coredns.Namespace", "empty"},
+			{"clusterlist", `duplicate ClusterName "prod-1" at [2] and [7]`},
+		}
+
+		assert.Equal(t, "the config has 2 problem(s):\n"+
+			"clusterlist[3].Components.coredns.Namespace: empty\n"+
+			`clusterlist: duplicate ClusterName "prod-1" at [2] and [7]`, validationErrors.Error())
+	})
+}
@@ -8,6 +8,7 @@ touch ~/.k8s-cluster-upgrade-tool/config.yaml
 
 cat > ~/.k8s-cluster-upgrade-tool/config.yaml <<EOF
 ---
+version: 3
 # the values below are just one above from the test values being installed
 components:
   aws-node: "v1.11.1"
@@ -18,24 +19,25 @@ clusterlist:
 - ClusterName: "kind-k8s-cluster-upgrade-tool-test-cluster"
   AwsRegion: "region1"
   AwsAccount: "account1"
-  AwsNodeObject:
-    ObjectType: "daemonset"
-    DeploymentName: "aws-node"
-    ContainerName: "aws-node"
-    Namespace: "kube-system"
-  ClusterAutoscalerObject:
-    ObjectType: "deployment"
-    DeploymentName: "cluster-autoscaler"
-    ContainerName: "aws-cluster-autoscaler"
-    Namespace: "kube-system"
-  CoreDnsObject:
-    DeploymentName: "coredns"
-    ObjectType: "deployment"
-    ContainerName: "coredns"
-    Namespace: "kube-system"
-  KubeProxyObject:
-    DeploymentName: "kube-proxy"
-    ObjectType: "daemonset"
-    ContainerName: "kube-proxy"
-    Namespace: "kube-system"
+  Components:
+    aws-node:
+      ObjectType: "daemonset"
+      DeploymentName: "aws-node"
+      ContainerName: "aws-node"
+      Namespace: "kube-system"
+    cluster-autoscaler:
+      ObjectType: "deployment"
+      DeploymentName: "cluster-autoscaler"
+      ContainerName: "aws-cluster-autoscaler"
+      Namespace: "kube-system"
+    coredns:
+      DeploymentName: "coredns"
+      ObjectType: "deployment"
+      ContainerName: "coredns"
+      Namespace: "kube-system"
+    kube-proxy:
+      DeploymentName: "kube-proxy"
+      ObjectType: "daemonset"
+      ContainerName: "kube-proxy"
+      Namespace: "kube-system"
 EOF
@@ -24,7 +24,7 @@ func TestAutoscalingGroup_SetMaxInstanceSizeToCurrentDesiredSize(t *testing.T) {
 		m := new(mockAutoScalingGroupApi)
 
 		m.On("UpdateAutoScalingGroupCount",
-			mock.AnythingOfType("*context.emptyCtx"), mock.AnythingOfType("aws.Config")).
+			mock.Anything, mock.AnythingOfType("aws.Config")).
 			Return(&autoscaling.UpdateAutoScalingGroupOutput{}, nil).
 			Once()
 
@@ -40,7 +40,7 @@ func TestAutoscalingGroup_SetMaxInstanceSizeToCurrentDesiredSize(t *testing.T) {
 		m := new(mockAutoScalingGroupApi)
 
 		m.On("UpdateAutoScalingGroupCount",
-			mock.AnythingOfType("*context.emptyCtx"), mock.AnythingOfType("aws.Config")).
+			mock.Anything, mock.AnythingOfType("aws.Config")).
 			Return(&autoscaling.UpdateAutoScalingGroupOutput{}, errors.New("some error")).
 			Once()
 
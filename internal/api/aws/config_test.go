@@ -24,7 +24,7 @@ func TestAwsConfig_LoadDefaultConfig(t *testing.T) {
 		m := new(mockAwsConfig)
 
 		m.On("LoadDefaultConfig",
-			mock.AnythingOfType("*context.emptyCtx"), mock.AnythingOfType("func(*config.LoadOptions) error"), mock.AnythingOfType("func(*config.LoadOptions) error")).
+			mock.Anything, mock.AnythingOfType("func(*config.LoadOptions) error"), mock.AnythingOfType("func(*config.LoadOptions) error")).
 			Return(aws.Config{Region: "correct-region"}, nil).
 			Once()
 
@@ -40,7 +40,7 @@ func TestAwsConfig_LoadDefaultConfig(t *testing.T) {
 		m := new(mockAwsConfig)
 
 		m.On("LoadDefaultConfig",
-			mock.AnythingOfType("*context.emptyCtx"), mock.AnythingOfType("func(*config.LoadOptions) error"), mock.AnythingOfType("func(*config.LoadOptions) error")).
+			mock.Anything, mock.AnythingOfType("func(*config.LoadOptions) error"), mock.AnythingOfType("func(*config.LoadOptions) error")).
 			Return(aws.Config{}, errors.New("some aws config error")).
 			Once()
 
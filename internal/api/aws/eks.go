@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"strings"
+)
+
+// EksCluster holds the attributes of an EKS cluster which the tool makes use of
+type EksCluster struct {
+	Name      string
+	Arn       string
+	Region    string
+	AccountId string
+	Endpoint  string
+}
+
+type DescribeEksClusterInterface interface {
+	DescribeEksCluster(ctx context.Context, cfg aws.Config, clusterName string) (*eks.DescribeClusterOutput, error)
+}
+
+type EksClient struct{}
+
+func (e *EksClient) DescribeEksCluster(ctx context.Context, cfg aws.Config, clusterName string) (*eks.DescribeClusterOutput, error) {
+	eksAwsClient := eks.NewFromConfig(cfg)
+	return eksAwsClient.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: aws.String(clusterName)})
+}
+
+type EksClusterGetter struct {
+	DescribeEksClusterInterface
+}
+
+// GetCluster describes the EKS cluster with the passed name in the account and region of the passed aws config
+func (e *EksClusterGetter) GetCluster(ctx context.Context, cfg aws.Config, clusterName string) (EksCluster, error) {
+	result, err := e.DescribeEksCluster(ctx, cfg, clusterName)
+	if err != nil {
+		return EksCluster{}, err
+	}
+	if result.Cluster == nil || result.Cluster.Arn == nil {
+		return EksCluster{}, fmt.Errorf("no EKS cluster was returned for the cluster name %s", clusterName)
+	}
+
+	eksCluster, err := ParseEksClusterArn(*result.Cluster.Arn)
+	if err != nil {
+		return EksCluster{}, err
+	}
+	if result.Cluster.Endpoint != nil {
+		eksCluster.Endpoint = *result.Cluster.Endpoint
+	}
+	return eksCluster, nil
+}
+
+// VerifyEndpoint returns an error when the API server endpoint passed, for example the server a kube context points to,
+// isn't the endpoint of the EKS cluster with the passed name
+func (e *EksClusterGetter) VerifyEndpoint(ctx context.Context, cfg aws.Config, clusterName, endpoint string) error {
+	eksCluster, err := e.GetCluster(ctx, cfg, clusterName)
+	if err != nil {
+		return err
+	}
+	if normalizeEndpoint(eksCluster.Endpoint) != normalizeEndpoint(endpoint) {
+		return fmt.Errorf("%s is not the endpoint of the EKS cluster %s, which is %s", endpoint, eksCluster.Arn, eksCluster.Endpoint)
+	}
+	return nil
+}
+
+func normalizeEndpoint(endpoint string) string {
+	return strings.ToLower(strings.TrimSuffix(endpoint, "/"))
+}
+
+// ParseEksClusterArn returns the name, region and account of an EKS cluster from its ARN, which is of the format
+// arn:aws:eks:eu-west-1:123456789012:cluster/cluster-name
+func ParseEksClusterArn(arn string) (EksCluster, error) {
+	arnParts := strings.SplitN(arn, ":", 6)
+	if len(arnParts) != 6 || arnParts[0] != "arn" || arnParts[2] != "eks" || !strings.HasPrefix(arnParts[5], "cluster/") {
+		return EksCluster{}, errors.New("invalid EKS cluster ARN passed")
+	}
+	return EksCluster{
+		Name:      strings.TrimPrefix(arnParts[5], "cluster/"),
+		Arn:       arn,
+		Region:    arnParts[3],
+		AccountId: arnParts[4],
+	}, nil
+}
+
+// ParseEksEndpointRegion returns the region of an EKS cluster from its API server endpoint, which is of the format
+// https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com
+func ParseEksEndpointRegion(endpoint string) (string, error) {
+	host := strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
+	host = strings.Split(strings.Split(host, "/")[0], ":")[0]
+	if !strings.HasSuffix(host, ".eks.amazonaws.com") {
+		return "", errors.New("the endpoint passed is not of an EKS cluster")
+	}
+	hostParts := strings.Split(strings.TrimSuffix(host, ".eks.amazonaws.com"), ".")
+	return hostParts[len(hostParts)-1], nil
+}
@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	"github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+)
+
+type mockEksApi struct {
+	mock.Mock
+}
+
+func (m *mockEksApi) DescribeEksCluster(ctx context.Context, cfg aws.Config, clusterName string) (*eks.DescribeClusterOutput, error) {
+	args := m.Called(ctx, cfg, clusterName)
+	return args.Get(0).(*eks.DescribeClusterOutput), args.Error(1)
+}
+
+func TestEksClusterGetter_GetCluster(t *testing.T) {
+	t.Run("when the describe cluster call is successful it returns the cluster", func(t *testing.T) {
+		m := new(mockEksApi)
+
+		m.On("DescribeEksCluster", mock.Anything, mock.AnythingOfType("aws.Config"), "valid-cluster-name").
+			Return(&eks.DescribeClusterOutput{Cluster: &types.Cluster{
+				Arn:      aws.String("arn:aws:eks:eu-west-1:123456789012:cluster/valid-cluster-name"),
+				Endpoint: aws.String("https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com"),
+			}}, nil).
+			Once()
+
+		s := EksClusterGetter{m}
+
+		result, err := s.GetCluster(context.TODO(), aws.Config{}, "valid-cluster-name")
+
+		assert.Nil(t, err)
+		assert.Equal(t, EksCluster{
+			Name:      "valid-cluster-name",
+			Arn:       "arn:aws:eks:eu-west-1:123456789012:cluster/valid-cluster-name",
+			Region:    "eu-west-1",
+			AccountId: "123456789012",
+			Endpoint:  "https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com",
+		}, result)
+	})
+
+	t.Run("when the describe cluster call is not successful it returns an error", func(t *testing.T) {
+		m := new(mockEksApi)
+
+		m.On("DescribeEksCluster", mock.Anything, mock.AnythingOfType("aws.Config"), "invalid-cluster-name").
+			Return(&eks.DescribeClusterOutput{}, errors.New("some error")).
+			Once()
+
+		s := EksClusterGetter{m}
+
+		result, err := s.GetCluster(context.TODO(), aws.Config{}, "invalid-cluster-name")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, EksCluster{}, result)
+	})
+}
+
+func TestEksClusterGetter_VerifyEndpoint(t *testing.T) {
+	newGetter := func() EksClusterGetter {
+		m := new(mockEksApi)
+		m.On("DescribeEksCluster", mock.Anything, mock.AnythingOfType("aws.Config"), "valid-cluster-name").
+			Return(&eks.DescribeClusterOutput{Cluster: &types.Cluster{
+				Arn:      aws.String("arn:aws:eks:eu-west-1:123456789012:cluster/valid-cluster-name"),
+				Endpoint: aws.String("https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com"),
+			}}, nil).
+			Once()
+		return EksClusterGetter{m}
+	}
+
+	t.Run("when the endpoint is the one of the EKS cluster it returns no error", func(t *testing.T) {
+		s := newGetter()
+
+		err := s.VerifyEndpoint(context.TODO(), aws.Config{}, "valid-cluster-name", "https://0123456789abcdef.gr7.eu-west-1.eks.amazonaws.com/")
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("when the endpoint is the one of another cluster it returns an error", func(t *testing.T) {
+		s := newGetter()
+
+		err := s.VerifyEndpoint(context.TODO(), aws.Config{}, "valid-cluster-name", "https://FEDCBA9876543210.gr7.eu-west-1.eks.amazonaws.com")
+
+		assert.EqualError(t, err, "https://FEDCBA9876543210.gr7.eu-west-1.eks.amazonaws.com is not the endpoint of the EKS cluster "+
+			"arn:aws:eks:eu-west-1:123456789012:cluster/valid-cluster-name, which is https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com")
+	})
+
+	t.Run("when the describe cluster call is not successful it returns an error", func(t *testing.T) {
+		m := new(mockEksApi)
+		m.On("DescribeEksCluster", mock.Anything, mock.AnythingOfType("aws.Config"), "invalid-cluster-name").
+			Return(&eks.DescribeClusterOutput{}, errors.New("some error")).
+			Once()
+		s := EksClusterGetter{m}
+
+		err := s.VerifyEndpoint(context.TODO(), aws.Config{}, "invalid-cluster-name", "https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com")
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestParseEksClusterArn(t *testing.T) {
+	tests := []struct {
+		name string
+		arn  string
+		want EksCluster
+		err  error
+	}{
+		{"when a valid ARN is passed it returns the name, region and account of the cluster",
+			"arn:aws:eks:us-east-1:123456789012:cluster/prod-1",
+			EksCluster{Name: "prod-1", Arn: "arn:aws:eks:us-east-1:123456789012:cluster/prod-1", Region: "us-east-1", AccountId: "123456789012"},
+			nil},
+		{"when the ARN is not of an EKS cluster it returns an error",
+			"arn:aws:iam::123456789012:role/foo", EksCluster{}, errors.New("invalid EKS cluster ARN passed")},
+		{"when a cluster name is passed it returns an error",
+			"prod-1", EksCluster{}, errors.New("invalid EKS cluster ARN passed")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEksClusterArn(tt.arn)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestParseEksEndpointRegion(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     string
+		err      error
+	}{
+		{"when an EKS endpoint is passed it returns the region",
+			"https://0123456789ABCDEF.gr7.eu-west-1.eks.amazonaws.com", "eu-west-1", nil},
+		{"when an EKS endpoint with a port is passed it returns the region",
+			"https://0123456789ABCDEF.yl4.ap-south-1.eks.amazonaws.com:443", "ap-south-1", nil},
+		{"when the endpoint is not of an EKS cluster it returns an error",
+			"https://127.0.0.1:6443", "", errors.New("the endpoint passed is not of an EKS cluster")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseEksEndpointRegion(tt.endpoint)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"encoding/json"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"log"
-	"os/exec"
-	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
@@ -108,35 +106,30 @@ func (a *AwsInstances) GetInstancesForASG(cfg aws.Config, asgName string, awsReg
 	}
 }
 
-// TODO Add a spec for this
-func (a AwsInstances) TaintNodes() error {
+// TaintNodes taints the nodes of the instances with the k8s.Taint
+func (a AwsInstances) TaintNodes(ctx context.Context, client k8s.Client) error {
 	for _, instance := range a {
 		log.Printf("Tainting node: %s\n", instance.PrivateDNS)
-		args := strings.Fields(k8s.KubectlTaintNodeCommand(instance.PrivateDNS))
-
-		output, err := exec.Command(args[0], args[1:]...).Output()
+		err := client.TaintNode(ctx, instance.PrivateDNS)
 		if err != nil {
 			log.Fatal("There was an error while tainting the node: ", err)
 			return err
 		}
-		log.Printf("taint output: \n %s", output)
+		log.Printf("%s has been tainted\n", instance.PrivateDNS)
 	}
 	return nil
 }
 
-// TODO Add a spec for this
-func (a AwsInstances) DrainNodes() error {
+// DrainNodes drains the nodes of the instances, the pods of daemonsets are left running
+func (a AwsInstances) DrainNodes(ctx context.Context, client k8s.Client) error {
 	for _, instance := range a {
 		log.Printf("Draining node: %s\n", instance.PrivateDNS)
-		args := strings.Fields(k8s.KubectlDrainNodeCommand(instance.PrivateDNS))
-
-		output, err := exec.Command(args[0], args[1:]...).Output()
+		err := client.DrainNode(ctx, instance.PrivateDNS)
 		if err != nil {
 			log.Fatal("There was an error while draining the node: ", err)
 			return err
 		}
-		log.Printf("drain output: \n %s", output)
-
+		log.Printf("%s has been drained\n", instance.PrivateDNS)
 	}
 	return nil
 }
@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// S3Object is the content of an S3 object along with its ETag, NotModified is set instead when the ETag passed is still
+// the current one of the object
+type S3Object struct {
+	Data        []byte
+	ETag        string
+	NotModified bool
+}
+
+type GetS3ObjectInterface interface {
+	GetS3Object(ctx context.Context, cfg aws.Config, input *s3.GetObjectInput) (*s3.GetObjectOutput, error)
+}
+
+type S3Client struct{}
+
+func (s *S3Client) GetS3Object(ctx context.Context, cfg aws.Config, input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	s3AwsClient := s3.NewFromConfig(cfg)
+	return s3AwsClient.GetObject(ctx, input)
+}
+
+type S3ObjectGetter struct {
+	GetS3ObjectInterface
+}
+
+// GetObject returns the S3 object of the bucket and key passed, unless the ETag passed is still the current one of the
+// object
+func (s *S3ObjectGetter) GetObject(ctx context.Context, cfg aws.Config, bucket, key, etag string) (S3Object, error) {
+	input := &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}
+	if etag != "" {
+		input.IfNoneMatch = aws.String(etag)
+	}
+
+	result, err := s.GetS3Object(ctx, cfg, input)
+	if err != nil {
+		var responseError *smithyhttp.ResponseError
+		if errors.As(err, &responseError) && responseError.HTTPStatusCode() == http.StatusNotModified {
+			return S3Object{ETag: etag, NotModified: true}, nil
+		}
+		return S3Object{}, err
+	}
+	defer result.Body.Close()
+
+	data, err := ioutil.ReadAll(result.Body)
+	if err != nil {
+		return S3Object{}, fmt.Errorf("error reading the S3 object s3://%s/%s: %s", bucket, key, err)
+	}
+	return S3Object{Data: data, ETag: aws.ToString(result.ETag)}, nil
+}
+
+// ParseS3Url returns the bucket and key of an S3 URL, which is of the format s3://bucket/path/to/key
+func ParseS3Url(s3Url string) (bucket, key string, err error) {
+	parsedUrl, err := url.Parse(s3Url)
+	if err != nil || parsedUrl.Scheme != "s3" || parsedUrl.Host == "" || strings.Trim(parsedUrl.Path, "/") == "" {
+		return "", "", fmt.Errorf("invalid S3 URL %s, it needs to be of the format s3://bucket/path/to/key", s3Url)
+	}
+	return parsedUrl.Host, strings.TrimPrefix(parsedUrl.Path, "/"), nil
+}
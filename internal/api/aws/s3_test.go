@@ -0,0 +1,101 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type mockS3Api struct {
+	mock.Mock
+}
+
+func (m *mockS3Api) GetS3Object(ctx context.Context, cfg aws.Config, input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	args := m.Called(ctx, cfg, input)
+	return args.Get(0).(*s3.GetObjectOutput), args.Error(1)
+}
+
+func TestS3ObjectGetter_GetObject(t *testing.T) {
+	t.Run("when the get object call is successful it returns the object and its ETag", func(t *testing.T) {
+		m := new(mockS3Api)
+
+		m.On("GetS3Object", mock.Anything, mock.AnythingOfType("aws.Config"),
+			&s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("path/config.yaml")}).
+			Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(strings.NewReader("components: {}\n")), ETag: aws.String(`"etag1"`)}, nil).
+			Once()
+
+		s := S3ObjectGetter{m}
+
+		result, err := s.GetObject(context.TODO(), aws.Config{}, "bucket", "path/config.yaml", "")
+
+		assert.Nil(t, err)
+		assert.Equal(t, S3Object{Data: []byte("components: {}\n"), ETag: `"etag1"`}, result)
+	})
+
+	t.Run("when the ETag passed is still the current one it returns that the object is not modified", func(t *testing.T) {
+		m := new(mockS3Api)
+		notModified := &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusNotModified}},
+			Err:      errors.New("not modified"),
+		}
+
+		m.On("GetS3Object", mock.Anything, mock.AnythingOfType("aws.Config"),
+			&s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("config.yaml"), IfNoneMatch: aws.String(`"etag1"`)}).
+			Return(&s3.GetObjectOutput{}, notModified).
+			Once()
+
+		s := S3ObjectGetter{m}
+
+		result, err := s.GetObject(context.TODO(), aws.Config{}, "bucket", "config.yaml", `"etag1"`)
+
+		assert.Nil(t, err)
+		assert.Equal(t, S3Object{ETag: `"etag1"`, NotModified: true}, result)
+	})
+
+	t.Run("when the get object call is not successful it returns an error", func(t *testing.T) {
+		m := new(mockS3Api)
+
+		m.On("GetS3Object", mock.Anything, mock.AnythingOfType("aws.Config"), mock.Anything).
+			Return(&s3.GetObjectOutput{}, errors.New("some error")).
+			Once()
+
+		s := S3ObjectGetter{m}
+
+		result, err := s.GetObject(context.TODO(), aws.Config{}, "bucket", "config.yaml", "")
+
+		assert.NotNil(t, err)
+		assert.Equal(t, S3Object{}, result)
+	})
+}
+
+func TestParseS3Url(t *testing.T) {
+	tests := []struct {
+		name       string
+		s3Url      string
+		wantBucket string
+		wantKey    string
+		err        error
+	}{
+		{"when the URL has a bucket and a key", "s3://bucket/path/to/config.yaml", "bucket", "path/to/config.yaml", nil},
+		{"when the URL has no key it returns an error", "s3://bucket/", "", "",
+			errors.New("invalid S3 URL s3://bucket/, it needs to be of the format s3://bucket/path/to/key")},
+		{"when the URL is not an S3 URL it returns an error", "https://bucket/config.yaml", "", "",
+			errors.New("invalid S3 URL https://bucket/config.yaml, it needs to be of the format s3://bucket/path/to/key")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBucket, gotKey, err := ParseS3Url(tt.s3Url)
+			assert.Equal(t, tt.wantBucket, gotBucket)
+			assert.Equal(t, tt.wantKey, gotKey)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
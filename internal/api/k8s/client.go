@@ -0,0 +1,326 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Taint is the taint the nodes are tainted with before they are drained
+var Taint = corev1.Taint{Key: "taintkey", Value: "k8s-cluster-upgrade-tool", Effect: corev1.TaintEffectNoSchedule}
+
+// Client runs the interactions of the tool with the cluster of a kube context
+type Client interface {
+	// GetImage returns the image of the container or init container of the daemonset, deployment or statefulset
+	GetImage(ctx context.Context, objectType, name, namespace, containerName string) (string, error)
+	// SetImage sets the image of the container or init container of the daemonset, deployment or statefulset
+	SetImage(ctx context.Context, objectType, name, namespace, containerName, image string) error
+	// ServerMinorVersion returns the kubernetes minor version, for example "1.27", of the cluster
+	ServerMinorVersion(ctx context.Context) (string, error)
+	// ListWorkloads returns the deployments, daemonsets and statefulsets of the namespace
+	ListWorkloads(ctx context.Context, namespace string) ([]Workload, error)
+	// TaintNode taints the node with the Taint, a node which already has it is left as it is
+	TaintNode(ctx context.Context, nodeName string) error
+	// CordonNode marks the node as unschedulable
+	CordonNode(ctx context.Context, nodeName string) error
+	// DrainNode cordons the node and evicts its pods, except the ones of daemonsets and the mirror pods, the same way
+	// kubectl drain --ignore-daemonsets --force --delete-emptydir-data does. It returns once the evicted pods are gone
+	DrainNode(ctx context.Context, nodeName string) error
+}
+
+// clientsetClient is the Client backed by a clientset of client-go
+type clientsetClient struct {
+	clientset kubernetes.Interface
+	// pollInterval is how often evictions blocked by a PodDisruptionBudget are retried and evicted pods are checked
+	pollInterval time.Duration
+	// drainTimeout is how long draining a node waits for its pods to be evicted
+	drainTimeout time.Duration
+}
+
+// NewClient returns a Client for the cluster of the kube context, which is read from the passed kubeconfig, or from the
+// kubeconfig in $KUBECONFIG or ~/.kube/config when none is passed. The kube context is only used by the Client, the
+// current context of the kubeconfig is never changed
+func NewClient(kubeconfig, kubeContext string) (Client, error) {
+	restConfig, err := kubeClientConfig(kubeconfig, kubeContext).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the kube context %s: %s", kubeContext, err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating a client for the kube context %s: %s", kubeContext, err)
+	}
+	return NewClientForClientset(clientset, 5*time.Second, 10*time.Minute), nil
+}
+
+// NewClientForClientset returns a Client running against the passed clientset, which retries blocked evictions and
+// checks evicted pods every pollInterval and gives up draining a node after drainTimeout
+func NewClientForClientset(clientset kubernetes.Interface, pollInterval, drainTimeout time.Duration) Client {
+	return clientsetClient{clientset: clientset, pollInterval: pollInterval, drainTimeout: drainTimeout}
+}
+
+// kubeClientConfig returns the config of the kube context read from the passed kubeconfig, or from the kubeconfig in
+// $KUBECONFIG or ~/.kube/config when none is passed
+func kubeClientConfig(kubeconfig, kubeContext string) clientcmd.ClientConfig {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+}
+
+func (c clientsetClient) GetImage(ctx context.Context, objectType, name, namespace, containerName string) (string, error) {
+	podTemplate, _, err := c.getPodTemplate(ctx, objectType, name, namespace)
+	if err != nil {
+		return "", err
+	}
+	container, err := findPodTemplateContainer(podTemplate, objectType, name, containerName)
+	if err != nil {
+		return "", err
+	}
+	return container.Image, nil
+}
+
+func (c clientsetClient) SetImage(ctx context.Context, objectType, name, namespace, containerName, image string) error {
+	podTemplate, update, err := c.getPodTemplate(ctx, objectType, name, namespace)
+	if err != nil {
+		return err
+	}
+	container, err := findPodTemplateContainer(podTemplate, objectType, name, containerName)
+	if err != nil {
+		return err
+	}
+	container.Image = image
+	return update()
+}
+
+// findPodTemplateContainer returns the container of the pod template with the passed name, which is looked up in the
+// containers and then in the init containers, like the aws-vpc-cni-init init container of aws-node
+func findPodTemplateContainer(podTemplate *corev1.PodTemplateSpec, objectType, name, containerName string) (*corev1.Container, error) {
+	var containerNames []string
+	for index := range podTemplate.Spec.Containers {
+		if podTemplate.Spec.Containers[index].Name == containerName {
+			return &podTemplate.Spec.Containers[index], nil
+		}
+		containerNames = append(containerNames, podTemplate.Spec.Containers[index].Name)
+	}
+	for index := range podTemplate.Spec.InitContainers {
+		if podTemplate.Spec.InitContainers[index].Name == containerName {
+			return &podTemplate.Spec.InitContainers[index], nil
+		}
+		containerNames = append(containerNames, podTemplate.Spec.InitContainers[index].Name+" (init)")
+	}
+	if len(containerNames) == 0 {
+		return nil, fmt.Errorf("the %s %s has no containers", objectType, name)
+	}
+	return nil, fmt.Errorf("the %s %s has no container named %s, its containers are %s", objectType, name, containerName,
+		strings.Join(containerNames, ", "))
+}
+
+// getPodTemplate returns the pod template of the daemonset, deployment or statefulset, along with a function updating
+// the object with the changes made to the pod template
+func (c clientsetClient) getPodTemplate(ctx context.Context, objectType, name, namespace string) (*corev1.PodTemplateSpec, func() error, error) {
+	apps := c.clientset.AppsV1()
+	switch objectType {
+	case "daemonset":
+		daemonSet, err := apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return &daemonSet.Spec.Template, func() error {
+			_, err := apps.DaemonSets(namespace).Update(ctx, daemonSet, metav1.UpdateOptions{})
+			return err
+		}, nil
+	case "deployment":
+		deployment, err := apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return &deployment.Spec.Template, func() error {
+			_, err := apps.Deployments(namespace).Update(ctx, deployment, metav1.UpdateOptions{})
+			return err
+		}, nil
+	case "statefulset":
+		statefulSet, err := apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, nil, err
+		}
+		return &statefulSet.Spec.Template, func() error {
+			_, err := apps.StatefulSets(namespace).Update(ctx, statefulSet, metav1.UpdateOptions{})
+			return err
+		}, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid object type %s, expected one of daemonset, deployment or statefulset", objectType)
+	}
+}
+
+func (c clientsetClient) ServerMinorVersion(ctx context.Context) (string, error) {
+	versionInfo, err := c.clientset.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	return ParseServerMinorVersion(versionInfo.GitVersion)
+}
+
+func (c clientsetClient) ListWorkloads(ctx context.Context, namespace string) ([]Workload, error) {
+	apps := c.clientset.AppsV1()
+	var workloads []Workload
+	deployments, err := apps.Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, deployment := range deployments.Items {
+		workloads = append(workloads, newWorkload("deployment", deployment.ObjectMeta, deployment.Spec.Template))
+	}
+	daemonSets, err := apps.DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, daemonSet := range daemonSets.Items {
+		workloads = append(workloads, newWorkload("daemonset", daemonSet.ObjectMeta, daemonSet.Spec.Template))
+	}
+	statefulSets, err := apps.StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		workloads = append(workloads, newWorkload("statefulset", statefulSet.ObjectMeta, statefulSet.Spec.Template))
+	}
+	return workloads, nil
+}
+
+func newWorkload(objectType string, objectMeta metav1.ObjectMeta, podTemplate corev1.PodTemplateSpec) Workload {
+	workload := Workload{ObjectType: objectType, Name: objectMeta.Name, Namespace: objectMeta.Namespace}
+	for _, containers := range [][]corev1.Container{podTemplate.Spec.Containers, podTemplate.Spec.InitContainers} {
+		for _, container := range containers {
+			workload.Containers = append(workload.Containers, Container{Name: container.Name, Image: container.Image})
+		}
+	}
+	return workload
+}
+
+func (c clientsetClient) TaintNode(ctx context.Context, nodeName string) error {
+	node, err := c.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.MatchTaint(&Taint) {
+			return nil
+		}
+	}
+	node.Spec.Taints = append(node.Spec.Taints, Taint)
+	_, err = c.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	return err
+}
+
+func (c clientsetClient) CordonNode(ctx context.Context, nodeName string) error {
+	node, err := c.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if node.Spec.Unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = true
+	_, err = c.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	return err
+}
+
+func (c clientsetClient) DrainNode(ctx context.Context, nodeName string) error {
+	err := c.CordonNode(ctx, nodeName)
+	if err != nil {
+		return err
+	}
+	pods, err := c.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.drainTimeout)
+	defer cancel()
+	var evictedPods []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != nodeName || isDaemonSetPod(pod) || isMirrorPod(pod) {
+			continue
+		}
+		err := c.evictPod(ctx, pod)
+		if err != nil {
+			return fmt.Errorf("error evicting the pod %s/%s: %s", pod.Namespace, pod.Name, err)
+		}
+		evictedPods = append(evictedPods, pod)
+	}
+	for _, pod := range evictedPods {
+		err := c.waitForPodDeletion(ctx, pod)
+		if err != nil {
+			return fmt.Errorf("error waiting for the pod %s/%s to be evicted: %s", pod.Namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// evictPod evicts the pod, retrying while a PodDisruptionBudget doesn't allow it
+func (c clientsetClient) evictPod(ctx context.Context, pod corev1.Pod) error {
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+	return wait.PollUntilContextCancel(ctx, c.pollInterval, true, func(ctx context.Context) (bool, error) {
+		err := c.clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		switch {
+		case err == nil || apierrors.IsNotFound(err):
+			return true, nil
+		case apierrors.IsTooManyRequests(err):
+			return false, nil
+		default:
+			return false, err
+		}
+	})
+}
+
+// waitForPodDeletion waits until the pod is gone, or replaced by a pod with the same name
+func (c clientsetClient) waitForPodDeletion(ctx context.Context, pod corev1.Pod) error {
+	return wait.PollUntilContextCancel(ctx, c.pollInterval, true, func(ctx context.Context) (bool, error) {
+		current, err := c.clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return current.UID != pod.UID, nil
+	})
+}
+
+func isDaemonSetPod(pod corev1.Pod) bool {
+	controller := metav1.GetControllerOf(&pod)
+	return controller != nil && controller.Kind == "DaemonSet" && controller.APIVersion == appsv1.SchemeGroupVersion.String()
+}
+
+func isMirrorPod(pod corev1.Pod) bool {
+	_, present := pod.Annotations[corev1.MirrorPodAnnotationKey]
+	return present
+}
+
+// ContextCluster returns the cluster the kube context points to, which is read from the passed kubeconfig, or from the
+// kubeconfig in $KUBECONFIG or ~/.kube/config when none is passed
+func ContextCluster(kubeconfig, kubeContext string) (KubeconfigCluster, error) {
+	rawConfig, err := kubeClientConfig(kubeconfig, kubeContext).RawConfig()
+	if err != nil {
+		return KubeconfigCluster{}, fmt.Errorf("error reading the kubeconfig: %s", err)
+	}
+	kubeconfigContext, present := rawConfig.Contexts[kubeContext]
+	if !present {
+		return KubeconfigCluster{}, fmt.Errorf("the kube context %s was not found in the kubeconfig", kubeContext)
+	}
+	cluster, present := rawConfig.Clusters[kubeconfigContext.Cluster]
+	if !present {
+		return KubeconfigCluster{}, errors.New("the kube context has no cluster")
+	}
+	return KubeconfigCluster{Name: kubeconfigContext.Cluster, Server: cluster.Server}, nil
+}
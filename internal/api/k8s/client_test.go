@@ -0,0 +1,396 @@
+package k8s_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+	"k8s-cluster-upgrade-tool/internal/api/k8s/k8sfake"
+)
+
+func podTemplate(containers ...corev1.Container) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}
+}
+
+func objectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{Name: name, Namespace: "kube-system"}
+}
+
+func TestClient_GetImage(t *testing.T) {
+	client := k8sfake.NewClient("v1.27.4",
+		&appsv1.Deployment{ObjectMeta: objectMeta("coredns"), Spec: appsv1.DeploymentSpec{Template: podTemplate(
+			corev1.Container{Name: "coredns", Image: "coredns/coredns:1.8.4"},
+			corev1.Container{Name: "sidecar", Image: "busybox:1.35"},
+		)}},
+		&appsv1.Deployment{ObjectMeta: objectMeta("cluster-autoscaler"), Spec: appsv1.DeploymentSpec{Template: podTemplate(
+			corev1.Container{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.12.0"},
+			corev1.Container{Name: "cluster-autoscaler", Image: "k8s.gcr.io/autoscaling/cluster-autoscaler:v1.20.0"},
+		)}},
+		&appsv1.DaemonSet{ObjectMeta: objectMeta("kube-proxy"), Spec: appsv1.DaemonSetSpec{Template: podTemplate(
+			corev1.Container{Name: "kube-proxy", Image: "k8s.gcr.io/kube-proxy:v1.20.15"},
+		)}},
+		&appsv1.DaemonSet{ObjectMeta: objectMeta("aws-node"), Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "aws-vpc-cni-init", Image: "602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni-init:v1.12.6"}},
+			Containers:     []corev1.Container{{Name: "aws-node", Image: "602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:v1.12.6"}},
+		}}}},
+		&appsv1.StatefulSet{ObjectMeta: objectMeta("empty")},
+	)
+	tests := []struct {
+		name          string
+		objectType    string
+		objectName    string
+		containerName string
+		want          string
+		err           string
+	}{
+		{"when the object is a deployment it returns the image of the container", "deployment", "coredns", "coredns",
+			"coredns/coredns:1.8.4", ""},
+		{"when the container isn't the first one it returns the image of the container", "deployment", "cluster-autoscaler",
+			"cluster-autoscaler", "k8s.gcr.io/autoscaling/cluster-autoscaler:v1.20.0", ""},
+		{"when the object is a daemonset it returns the image of the container", "daemonset", "kube-proxy", "kube-proxy",
+			"k8s.gcr.io/kube-proxy:v1.20.15", ""},
+		{"when the container is an init container it returns its image", "daemonset", "aws-node", "aws-vpc-cni-init",
+			"602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni-init:v1.12.6", ""},
+		{"when the container doesn't exist it returns an error listing the containers", "daemonset", "aws-node", "cni",
+			"", "the daemonset aws-node has no container named cni, its containers are aws-node, aws-vpc-cni-init (init)"},
+		{"when the object has no containers it returns an error", "statefulset", "empty", "empty",
+			"", "the statefulset empty has no containers"},
+		{"when the object doesn't exist it returns an error", "deployment", "kube-proxy", "kube-proxy",
+			"", `deployments.apps "kube-proxy" not found`},
+		{"when the object type is not valid it returns an error", "pod", "coredns", "coredns",
+			"", "invalid object type pod, expected one of daemonset, deployment or statefulset"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.GetImage(context.TODO(), tt.objectType, tt.objectName, "kube-system", tt.containerName)
+			assert.Equal(t, tt.want, got)
+			if tt.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestClient_SetImage(t *testing.T) {
+	newClient := func() k8sfake.Client {
+		return k8sfake.NewClient("v1.27.4", &appsv1.Deployment{ObjectMeta: objectMeta("cluster-autoscaler"), Spec: appsv1.DeploymentSpec{
+			Template: podTemplate(
+				corev1.Container{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.12.0"},
+				corev1.Container{Name: "cluster-autoscaler", Image: "k8s.gcr.io/autoscaling/cluster-autoscaler:v1.20.0"},
+			),
+		}})
+	}
+
+	t.Run("when the container exists its image is set", func(t *testing.T) {
+		client := newClient()
+
+		err := client.SetImage(context.TODO(), "deployment", "cluster-autoscaler", "kube-system", "cluster-autoscaler",
+			"k8s.gcr.io/autoscaling/cluster-autoscaler:v1.21.0")
+
+		assert.Nil(t, err)
+		deployment, err := client.Clientset.AppsV1().Deployments("kube-system").Get(context.TODO(), "cluster-autoscaler", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, []corev1.Container{
+			{Name: "istio-proxy", Image: "docker.io/istio/proxyv2:1.12.0"},
+			{Name: "cluster-autoscaler", Image: "k8s.gcr.io/autoscaling/cluster-autoscaler:v1.21.0"},
+		}, deployment.Spec.Template.Spec.Containers)
+	})
+
+	t.Run("when the container doesn't exist it returns an error", func(t *testing.T) {
+		err := newClient().SetImage(context.TODO(), "deployment", "cluster-autoscaler", "kube-system", "autoscaler", "autoscaler:v1")
+
+		assert.EqualError(t, err, "the deployment cluster-autoscaler has no container named autoscaler, its containers are "+
+			"istio-proxy, cluster-autoscaler")
+	})
+
+	t.Run("when the container is an init container its image is set", func(t *testing.T) {
+		client := k8sfake.NewClient("v1.27.4", &appsv1.DaemonSet{ObjectMeta: objectMeta("aws-node"), Spec: appsv1.DaemonSetSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "aws-vpc-cni-init", Image: "amazon-k8s-cni-init:v1.12.6"}},
+				Containers:     []corev1.Container{{Name: "aws-node", Image: "amazon-k8s-cni:v1.12.6"}},
+			}},
+		}})
+
+		err := client.SetImage(context.TODO(), "daemonset", "aws-node", "kube-system", "aws-vpc-cni-init", "amazon-k8s-cni-init:v1.13.0")
+
+		assert.Nil(t, err)
+		daemonSet, err := client.Clientset.AppsV1().DaemonSets("kube-system").Get(context.TODO(), "aws-node", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, []corev1.Container{{Name: "aws-vpc-cni-init", Image: "amazon-k8s-cni-init:v1.13.0"}}, daemonSet.Spec.Template.Spec.InitContainers)
+		assert.Equal(t, []corev1.Container{{Name: "aws-node", Image: "amazon-k8s-cni:v1.12.6"}}, daemonSet.Spec.Template.Spec.Containers)
+	})
+}
+
+func TestClient_ServerMinorVersion(t *testing.T) {
+	t.Run("when the server runs an EKS version it returns the minor version", func(t *testing.T) {
+		got, err := k8sfake.NewClient("v1.27.4-eks-2d98532").ServerMinorVersion(context.TODO())
+
+		assert.Nil(t, err)
+		assert.Equal(t, "1.27", got)
+	})
+
+	t.Run("when the server version is not valid it returns an error", func(t *testing.T) {
+		_, err := k8sfake.NewClient("foo").ServerMinorVersion(context.TODO())
+
+		assert.EqualError(t, err, "invalid server version foo")
+	})
+}
+
+func TestClient_ListWorkloads(t *testing.T) {
+	client := k8sfake.NewClient("v1.27.4",
+		&appsv1.Deployment{ObjectMeta: objectMeta("coredns"), Spec: appsv1.DeploymentSpec{Template: podTemplate(
+			corev1.Container{Name: "coredns", Image: "coredns/coredns:1.8.4"},
+		)}},
+		&appsv1.DaemonSet{ObjectMeta: objectMeta("kube-proxy"), Spec: appsv1.DaemonSetSpec{Template: podTemplate(
+			corev1.Container{Name: "kube-proxy", Image: "k8s.gcr.io/kube-proxy:v1.20.15"},
+		)}},
+		&appsv1.DaemonSet{ObjectMeta: objectMeta("aws-node"), Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "aws-vpc-cni-init", Image: "amazon-k8s-cni-init:v1.12.6"}},
+			Containers:     []corev1.Container{{Name: "aws-node", Image: "amazon-k8s-cni:v1.12.6"}},
+		}}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}},
+	)
+
+	got, err := client.ListWorkloads(context.TODO(), "kube-system")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []k8s.Workload{
+		{ObjectType: "deployment", Name: "coredns", Namespace: "kube-system", Containers: []k8s.Container{{Name: "coredns", Image: "coredns/coredns:1.8.4"}}},
+		{ObjectType: "daemonset", Name: "aws-node", Namespace: "kube-system", Containers: []k8s.Container{
+			{Name: "aws-node", Image: "amazon-k8s-cni:v1.12.6"}, {Name: "aws-vpc-cni-init", Image: "amazon-k8s-cni-init:v1.12.6"},
+		}},
+		{ObjectType: "daemonset", Name: "kube-proxy", Namespace: "kube-system", Containers: []k8s.Container{{Name: "kube-proxy", Image: "k8s.gcr.io/kube-proxy:v1.20.15"}}},
+	}, got)
+}
+
+func TestClient_TaintNode(t *testing.T) {
+	otherTaint := corev1.Taint{Key: "dedicated", Value: "spot", Effect: corev1.TaintEffectNoSchedule}
+	tests := []struct {
+		name   string
+		taints []corev1.Taint
+		want   []corev1.Taint
+	}{
+		{"when the node has no taints it is tainted", nil, []corev1.Taint{k8s.Taint}},
+		{"when the node has other taints they are kept", []corev1.Taint{otherTaint}, []corev1.Taint{otherTaint, k8s.Taint}},
+		{"when the node is already tainted it is left as it is", []corev1.Taint{k8s.Taint}, []corev1.Taint{k8s.Taint}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := k8sfake.NewClient("v1.27.4", &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}, Spec: corev1.NodeSpec{Taints: tt.taints}})
+
+			err := client.TaintNode(context.TODO(), "node1")
+
+			assert.Nil(t, err)
+			node, err := client.Clientset.CoreV1().Nodes().Get(context.TODO(), "node1", metav1.GetOptions{})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.want, node.Spec.Taints)
+		})
+	}
+
+	t.Run("when the node doesn't exist it returns an error", func(t *testing.T) {
+		err := k8sfake.NewClient("v1.27.4").TaintNode(context.TODO(), "node1")
+
+		assert.EqualError(t, err, `nodes "node1" not found`)
+	})
+}
+
+func TestClient_DrainNode(t *testing.T) {
+	pod := func(name, nodeName string, modify func(pod *corev1.Pod)) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Spec: corev1.PodSpec{NodeName: nodeName}}
+		if modify != nil {
+			modify(pod)
+		}
+		return pod
+	}
+	isController := true
+	objects := []runtime.Object{
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}},
+		pod("app", "node1", nil),
+		pod("other-node-app", "node2", nil),
+		pod("aws-node", "node1", func(pod *corev1.Pod) {
+			pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "DaemonSet", Name: "aws-node", Controller: &isController}}
+		}),
+		pod("static", "node1", func(pod *corev1.Pod) {
+			pod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "hash"}
+		}),
+	}
+	podNames := func(t *testing.T, client k8sfake.Client) []string {
+		pods, err := client.Clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+		assert.Nil(t, err)
+		var names []string
+		for _, pod := range pods.Items {
+			names = append(names, pod.Name)
+		}
+		return names
+	}
+
+	t.Run("when the node has pods it is cordoned and the pods which aren't of daemonsets or mirror pods are evicted", func(t *testing.T) {
+		client := k8sfake.NewClient("v1.27.4", objects...)
+
+		err := client.DrainNode(context.TODO(), "node1")
+
+		assert.Nil(t, err)
+		node, err := client.Clientset.CoreV1().Nodes().Get(context.TODO(), "node1", metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.True(t, node.Spec.Unschedulable)
+		assert.ElementsMatch(t, []string{"other-node-app", "aws-node", "static"}, podNames(t, client))
+	})
+
+	t.Run("when a PodDisruptionBudget blocks an eviction it is retried", func(t *testing.T) {
+		client := k8sfake.NewClient("v1.27.4", objects...)
+		blockedEvictions := 2
+		client.Clientset.PrependReactor("create", "pods",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" || blockedEvictions == 0 {
+					return false, nil, nil
+				}
+				blockedEvictions--
+				return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+			})
+
+		err := client.DrainNode(context.TODO(), "node1")
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, blockedEvictions)
+		assert.NotContains(t, podNames(t, client), "app")
+	})
+
+	t.Run("when an eviction fails it returns an error", func(t *testing.T) {
+		client := k8sfake.NewClient("v1.27.4", objects...)
+		client.Clientset.PrependReactor("create", "pods",
+			func(action k8stesting.Action) (bool, runtime.Object, error) {
+				return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "app", nil)
+			})
+
+		err := client.DrainNode(context.TODO(), "node1")
+
+		assert.EqualError(t, err, `error evicting the pod default/app: pods "app" is forbidden: <nil>`)
+	})
+}
+
+func TestContextCluster(t *testing.T) {
+	kubeconfig := writeKubeconfig(t, `apiVersion: v1
+kind: Config
+clusters:
+- name: arn:aws:eks:eu-west-1:123456789012:cluster/prod-1
+  cluster:
+    server: https://ABCDEF.gr7.eu-west-1.eks.amazonaws.com
+contexts:
+- name: prod-1
+  context:
+    cluster: arn:aws:eks:eu-west-1:123456789012:cluster/prod-1
+- name: no-cluster
+  context:
+    cluster: foo
+current-context: prod-1
+`)
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	tests := []struct {
+		name        string
+		kubeContext string
+		want        k8s.KubeconfigCluster
+		err         string
+	}{
+		{"when the context has a cluster it returns its name and server", "prod-1",
+			k8s.KubeconfigCluster{Name: "arn:aws:eks:eu-west-1:123456789012:cluster/prod-1", Server: "https://ABCDEF.gr7.eu-west-1.eks.amazonaws.com"}, ""},
+		{"when the cluster of the context doesn't exist it returns an error", "no-cluster",
+			k8s.KubeconfigCluster{}, "the kube context has no cluster"},
+		{"when the context doesn't exist it returns an error", "foo",
+			k8s.KubeconfigCluster{}, "the kube context foo was not found in the kubeconfig"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := k8s.ContextCluster("", tt.kubeContext)
+			assert.Equal(t, tt.want, got)
+			if tt.err == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+
+	t.Run("when a kubeconfig is passed it is read instead of the one of KUBECONFIG", func(t *testing.T) {
+		passedKubeconfig := writeKubeconfig(t, `apiVersion: v1
+kind: Config
+clusters:
+- name: staging-1
+  cluster:
+    server: https://staging-1.example.com
+contexts:
+- name: staging-1
+  context:
+    cluster: staging-1
+`)
+
+		got, err := k8s.ContextCluster(passedKubeconfig, "staging-1")
+
+		assert.Nil(t, err)
+		assert.Equal(t, k8s.KubeconfigCluster{Name: "staging-1", Server: "https://staging-1.example.com"}, got)
+		_, err = k8s.ContextCluster(passedKubeconfig, "prod-1")
+		assert.EqualError(t, err, "the kube context prod-1 was not found in the kubeconfig")
+	})
+
+	t.Run("when the passed kubeconfig doesn't exist it returns an error", func(t *testing.T) {
+		_, err := k8s.ContextCluster(filepath.Join(t.TempDir(), "config"), "prod-1")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestNewClient(t *testing.T) {
+	kubeconfigYaml := `apiVersion: v1
+kind: Config
+clusters:
+- name: prod-1
+  cluster:
+    server: https://prod-1.example.com
+- name: staging-1
+  cluster:
+    server: https://staging-1.example.com
+contexts:
+- name: prod-1
+  context:
+    cluster: prod-1
+- name: staging-1
+  context:
+    cluster: staging-1
+current-context: prod-1
+`
+	kubeconfig := writeKubeconfig(t, kubeconfigYaml)
+
+	t.Run("when a client is created for a context the current context of the kubeconfig is left as it is", func(t *testing.T) {
+		_, err := k8s.NewClient(kubeconfig, "staging-1")
+
+		assert.Nil(t, err)
+		data, err := ioutil.ReadFile(kubeconfig)
+		assert.Nil(t, err)
+		assert.Equal(t, kubeconfigYaml, string(data))
+	})
+
+	t.Run("when a client is created for a context which doesn't exist it returns an error", func(t *testing.T) {
+		_, err := k8s.NewClient(kubeconfig, "foo")
+
+		assert.Error(t, err)
+	})
+}
+
+func writeKubeconfig(t *testing.T, data string) string {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	assert.Nil(t, ioutil.WriteFile(kubeconfig, []byte(data), 0644))
+	return kubeconfig
+}
@@ -7,31 +7,24 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestParseComponentImage(t *testing.T) {
-	type args struct {
-		kubectlExecOutput string
-		imageSection      string
-	}
+func TestParseServerMinorVersion(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want string
-		err  error
+		name       string
+		gitVersion string
+		want       string
+		err        error
 	}{
-		{"when getComponentImageTag is passed with a valid output and imageSection and it returns the image tag",
-			args{"'my-hash.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:my-version'", "imageTag"},
-			"my-version", nil},
-		{"when getComponentImageTag is passed with a valid output and imagePrefix and it returns the image tag",
-			args{"'my-hash.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:my-version'", "imagePrefix"},
-			"my-hash.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni", nil},
-		{"when getComponentImageTag is passed with a valid output and imagePrefix and it returns the image tag",
-			args{"'my-hash.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:my-version'", "foo"},
-			"", errors.New("invalid imageSection Passed")},
+		{"when the server version is an EKS version it returns the minor version",
+			"v1.27.4-eks-2d98532", "1.27", nil},
+		{"when the server version has no suffix it returns the minor version",
+			"v1.20.15", "1.20", nil},
+		{"when the server version is not valid it returns an error",
+			"foo", "", errors.New("invalid server version foo")},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := ParseComponentImage(tt.args.kubectlExecOutput, tt.args.imageSection)
-			assert.Equal(t, got, tt.want)
+			got, err := ParseServerMinorVersion(tt.gitVersion)
+			assert.Equal(t, tt.want, got)
 			assert.Equal(t, tt.err, err)
 		})
 	}
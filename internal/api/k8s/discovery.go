@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"strings"
+)
+
+// KnownComponent describes how a component is usually deployed, which is used to find it in a cluster
+type KnownComponent struct {
+	Name string
+	// WorkloadNames are the names the workload of the component is usually deployed with
+	WorkloadNames []string
+	// ImageNames are the last path segments of the image repositories the component is released with
+	ImageNames []string
+}
+
+// KnownComponents are the components which can be discovered in a cluster
+var KnownComponents = []KnownComponent{
+	{Name: "aws-node", WorkloadNames: []string{"aws-node"}, ImageNames: []string{"amazon-k8s-cni"}},
+	{Name: "cluster-autoscaler", WorkloadNames: []string{"cluster-autoscaler", "cluster-autoscaler-aws-cluster-autoscaler"}, ImageNames: []string{"cluster-autoscaler"}},
+	{Name: "coredns", WorkloadNames: []string{"coredns"}, ImageNames: []string{"coredns"}},
+	{Name: "kube-proxy", WorkloadNames: []string{"kube-proxy"}, ImageNames: []string{"kube-proxy"}},
+	{Name: "ebs-csi-driver", WorkloadNames: []string{"ebs-csi-controller"}, ImageNames: []string{"aws-ebs-csi-driver"}},
+	{Name: "metrics-server", WorkloadNames: []string{"metrics-server"}, ImageNames: []string{"metrics-server"}},
+}
+
+// Workload is a deployment, daemonset or statefulset running in the cluster
+type Workload struct {
+	ObjectType string
+	Name       string
+	Namespace  string
+	// Containers are the containers of the workload followed by its init containers
+	Containers []Container
+}
+
+type Container struct {
+	Name  string
+	Image string
+}
+
+// DiscoveredComponent is the workload and container a component was found running as
+type DiscoveredComponent struct {
+	ObjectType     string
+	DeploymentName string
+	ContainerName  string
+	Namespace      string
+}
+
+// KubeconfigCluster is the cluster a kubeconfig context points to
+type KubeconfigCluster struct {
+	Name   string
+	Server string
+}
+
+// DiscoverComponents returns the workload and container each of the KnownComponents is running as, a workload is
+// matched by its name first and then by the images of its containers. Components which are not found are left out
+func DiscoverComponents(workloads []Workload) map[string]DiscoveredComponent {
+	discoveredComponents := map[string]DiscoveredComponent{}
+	for _, knownComponent := range KnownComponents {
+		workload, found := findWorkloadByName(workloads, knownComponent)
+		if !found {
+			workload, found = findWorkloadByImage(workloads, knownComponent)
+		}
+		if !found || len(workload.Containers) == 0 {
+			continue
+		}
+
+		discoveredComponents[knownComponent.Name] = DiscoveredComponent{
+			ObjectType:     workload.ObjectType,
+			DeploymentName: workload.Name,
+			ContainerName:  findContainer(workload, knownComponent).Name,
+			Namespace:      workload.Namespace,
+		}
+	}
+	return discoveredComponents
+}
+
+func findWorkloadByName(workloads []Workload, knownComponent KnownComponent) (Workload, bool) {
+	for _, workload := range workloads {
+		for _, workloadName := range knownComponent.WorkloadNames {
+			if workload.Name == workloadName {
+				return workload, true
+			}
+		}
+	}
+	return Workload{}, false
+}
+
+func findWorkloadByImage(workloads []Workload, knownComponent KnownComponent) (Workload, bool) {
+	for _, workload := range workloads {
+		for _, container := range workload.Containers {
+			if hasImageName(container.Image, knownComponent.ImageNames) {
+				return workload, true
+			}
+		}
+	}
+	return Workload{}, false
+}
+
+// findContainer returns the container of the workload running the image of the component, falling back to the
+// container named after the component and then the first container
+func findContainer(workload Workload, knownComponent KnownComponent) Container {
+	for _, container := range workload.Containers {
+		if hasImageName(container.Image, knownComponent.ImageNames) {
+			return container
+		}
+	}
+	for _, container := range workload.Containers {
+		if container.Name == knownComponent.Name {
+			return container
+		}
+	}
+	return workload.Containers[0]
+}
+
+func hasImageName(image string, imageNames []string) bool {
+	imageReference, err := ParseImageReference(image)
+	if err != nil {
+		return false
+	}
+	repository := imageReference.Repository[strings.LastIndex(imageReference.Repository, "/")+1:]
+	for _, imageName := range imageNames {
+		if repository == imageName {
+			return true
+		}
+	}
+	return false
+}
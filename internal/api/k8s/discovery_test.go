@@ -0,0 +1,39 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverComponents(t *testing.T) {
+	workloads := []Workload{
+		{ObjectType: "daemonset", Name: "aws-node", Namespace: "kube-system", Containers: []Container{
+			{"aws-node", "602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:v1.11.0"},
+		}},
+		{ObjectType: "deployment", Name: "coredns", Namespace: "kube-system", Containers: []Container{
+			{"coredns", "602401143452.dkr.ecr.eu-west-1.amazonaws.com/eks/coredns:v1.8.4-eksbuild.1"},
+		}},
+		{ObjectType: "deployment", Name: "autoscaler", Namespace: "kube-system", Containers: []Container{
+			{"istio-proxy", "docker.io/istio/proxyv2:1.12.0"},
+			{"aws-cluster-autoscaler", "k8s.gcr.io/autoscaling/cluster-autoscaler:v1.20.0"},
+		}},
+		{ObjectType: "daemonset", Name: "kube-proxy", Namespace: "kube-system", Containers: []Container{
+			{"sidecar", "busybox@sha256:0123456789abcdef"},
+			{"main", "k8s.gcr.io/kube-proxy:v1.20.15"},
+		}},
+	}
+
+	t.Run("returns the workload and container of every component found by workload name or image", func(t *testing.T) {
+		assert.Equal(t, map[string]DiscoveredComponent{
+			"aws-node":           {ObjectType: "daemonset", DeploymentName: "aws-node", ContainerName: "aws-node", Namespace: "kube-system"},
+			"coredns":            {ObjectType: "deployment", DeploymentName: "coredns", ContainerName: "coredns", Namespace: "kube-system"},
+			"cluster-autoscaler": {ObjectType: "deployment", DeploymentName: "autoscaler", ContainerName: "aws-cluster-autoscaler", Namespace: "kube-system"},
+			"kube-proxy":         {ObjectType: "daemonset", DeploymentName: "kube-proxy", ContainerName: "main", Namespace: "kube-system"},
+		}, DiscoverComponents(workloads))
+	})
+
+	t.Run("returns no components when no workloads are passed", func(t *testing.T) {
+		assert.Equal(t, map[string]DiscoveredComponent{}, DiscoverComponents(nil))
+	})
+}
@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// the grammar of the components of a repository, a tag and a digest, as per the OCI distribution spec
+	repositoryComponentRegex = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*$`)
+	tagRegex                 = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	digestRegex              = regexp.MustCompile(`^[a-z0-9]+(?:[+._-][a-z0-9]+)*:[A-Fa-f0-9]{32,}$`)
+)
+
+// ImageReference is an image reference like registry.example.com:5000/eks/coredns:v1.10.1@sha256:..., of which the
+// Registry, Tag and Digest are optional
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference parses an image reference, as returned by Client.GetImage. The first component of the image is
+// its Registry when it has a dot or a port, or is localhost, like the container runtimes do, no default registry is
+// filled in for images without one
+func ParseImageReference(image string) (ImageReference, error) {
+	var reference ImageReference
+	name := image
+	if separator := strings.Index(name, "@"); separator != -1 {
+		name, reference.Digest = name[:separator], name[separator+1:]
+		if !digestRegex.MatchString(reference.Digest) {
+			return ImageReference{}, fmt.Errorf("invalid image %s, %q is not a valid digest", image, reference.Digest)
+		}
+	}
+	if separator := strings.LastIndex(name, ":"); separator > strings.LastIndex(name, "/") {
+		name, reference.Tag = name[:separator], name[separator+1:]
+		if !tagRegex.MatchString(reference.Tag) {
+			return ImageReference{}, fmt.Errorf("invalid image %s, %q is not a valid tag", image, reference.Tag)
+		}
+	}
+	if separator := strings.Index(name, "/"); separator != -1 {
+		if firstComponent := name[:separator]; strings.ContainsAny(firstComponent, ".:") || firstComponent == "localhost" {
+			reference.Registry, name = firstComponent, name[separator+1:]
+		}
+	}
+	if name == "" {
+		return ImageReference{}, fmt.Errorf("invalid image %s, it has no repository", image)
+	}
+	for _, component := range strings.Split(name, "/") {
+		if !repositoryComponentRegex.MatchString(component) {
+			return ImageReference{}, fmt.Errorf("invalid image %s, %s is not a valid repository", image, name)
+		}
+	}
+	reference.Repository = name
+	return reference, nil
+}
+
+// Name returns the image without its tag and digest, which is the image repository the components are configured with
+func (r ImageReference) Name() string {
+	if r.Registry == "" {
+		return r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the image reference
+func (r ImageReference) String() string {
+	image := r.Name()
+	if r.Tag != "" {
+		image += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		image += "@" + r.Digest
+	}
+	return image
+}
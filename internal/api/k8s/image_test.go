@@ -0,0 +1,92 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseImageReference(t *testing.T) {
+	const digest = "sha256:4a6a6b1b6d8d1b5c6e0c0b1f2b9b2d3b1c5c7e6f9a8b7c6d5e4f3a2b1c0d9e8f"
+	tests := []struct {
+		name  string
+		image string
+		want  ImageReference
+		err   error
+	}{
+		{"when the image is from an ECR registry it returns the registry, repository and tag",
+			"602401143452.dkr.ecr.eu-west-1.amazonaws.com/amazon-k8s-cni:v1.12.6-eksbuild.2",
+			ImageReference{Registry: "602401143452.dkr.ecr.eu-west-1.amazonaws.com", Repository: "amazon-k8s-cni", Tag: "v1.12.6-eksbuild.2"},
+			nil},
+		{"when the registry has a port it isn't mistaken for the tag",
+			"registry.local:5000/coredns:1.8.4",
+			ImageReference{Registry: "registry.local:5000", Repository: "coredns", Tag: "1.8.4"},
+			nil},
+		{"when the registry has a port and the image has no tag it returns no tag",
+			"registry.local:5000/eks/coredns",
+			ImageReference{Registry: "registry.local:5000", Repository: "eks/coredns"},
+			nil},
+		{"when the registry is localhost it returns it as the registry",
+			"localhost/coredns:1.8.4",
+			ImageReference{Registry: "localhost", Repository: "coredns", Tag: "1.8.4"},
+			nil},
+		{"when the image has no registry the first component is part of the repository",
+			"eks/coredns:v1.10.1-eksbuild.2",
+			ImageReference{Repository: "eks/coredns", Tag: "v1.10.1-eksbuild.2"},
+			nil},
+		{"when the image only has a repository it returns no registry and no tag",
+			"coredns",
+			ImageReference{Repository: "coredns"},
+			nil},
+		{"when the image is pinned to a digest it returns the digest and no tag",
+			"registry.k8s.io/coredns/coredns@" + digest,
+			ImageReference{Registry: "registry.k8s.io", Repository: "coredns/coredns", Digest: digest},
+			nil},
+		{"when the image has a tag and a digest it returns both",
+			"registry.local:5000/coredns:1.8.4@" + digest,
+			ImageReference{Registry: "registry.local:5000", Repository: "coredns", Tag: "1.8.4", Digest: digest},
+			nil},
+		{"when the image is empty it returns an error",
+			"", ImageReference{}, errors.New("invalid image , it has no repository")},
+		{"when the image only has a registry it returns an error",
+			"registry.local:5000/", ImageReference{}, errors.New("invalid image registry.local:5000/, it has no repository")},
+		{"when the tag is empty it returns an error",
+			"coredns:", ImageReference{}, errors.New(`invalid image coredns:, "" is not a valid tag`)},
+		{"when the digest is not valid it returns an error",
+			"coredns@sha256:foo", ImageReference{}, errors.New(`invalid image coredns@sha256:foo, "sha256:foo" is not a valid digest`)},
+		{"when the repository has upper case letters it returns an error",
+			"eks/CoreDNS:1.8.4", ImageReference{}, errors.New("invalid image eks/CoreDNS:1.8.4, eks/CoreDNS is not a valid repository")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImageReference(tt.image)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestImageReference_String(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference ImageReference
+		wantName  string
+		want      string
+	}{
+		{"when the image has a registry and a tag",
+			ImageReference{Registry: "registry.local:5000", Repository: "eks/coredns", Tag: "1.8.4"},
+			"registry.local:5000/eks/coredns", "registry.local:5000/eks/coredns:1.8.4"},
+		{"when the image has no registry",
+			ImageReference{Repository: "coredns", Tag: "1.8.4"}, "coredns", "coredns:1.8.4"},
+		{"when the image has a tag and a digest",
+			ImageReference{Registry: "registry.k8s.io", Repository: "coredns", Tag: "1.8.4", Digest: "sha256:abc"},
+			"registry.k8s.io/coredns", "registry.k8s.io/coredns:1.8.4@sha256:abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantName, tt.reference.Name())
+			assert.Equal(t, tt.want, tt.reference.String())
+		})
+	}
+}
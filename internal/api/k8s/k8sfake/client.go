@@ -0,0 +1,38 @@
+package k8sfake
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"k8s-cluster-upgrade-tool/internal/api/k8s"
+)
+
+// Client is a k8s.Client backed by a fake clientset, to test the commands without a cluster. It is only imported by
+// tests, so the fake clientset isn't built into the binary
+type Client struct {
+	k8s.Client
+	// Clientset is the fake clientset the Client runs against, to check the objects the Client changed
+	Clientset *fake.Clientset
+}
+
+// NewClient returns a Client holding the passed objects, whose server runs the passed git version, for example
+// v1.27.4-eks-2d98532. Evicted pods are deleted right away, so nodes can be drained
+func NewClient(gitVersion string, objects ...runtime.Object) Client {
+	clientset := fake.NewClientset(objects...)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{GitVersion: gitVersion}
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(k8stesting.CreateAction).GetObject().(metav1.Object)
+		err := clientset.Tracker().Delete(action.GetResource(), eviction.GetNamespace(), eviction.GetName())
+		return true, nil, err
+	})
+	return Client{Client: k8s.NewClientForClientset(clientset, time.Millisecond, time.Second), Clientset: clientset}
+}
@@ -0,0 +1,293 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionRegex matches versions like 1.8.4, v1.20.14, v1.10.1-eksbuild.2, v1.27.1-minimal-eksbuild.1 or v1.2.0-rc.1,
+// the minor and patch versions are optional so partial versions like 1.10 can be used in constraints
+var versionRegex = regexp.MustCompile(`^v?([0-9]+)(?:\.([0-9]+))?(?:\.([0-9]+))?(?:-([0-9A-Za-z.-]+))?$`)
+
+// eksBuildRegex matches the suffix EKS adds to the versions of the images it builds, for example eksbuild.2 or
+// minimal-eksbuild.1, which are builds of the released version and not pre-releases of it
+var eksBuildRegex = regexp.MustCompile(`^(?:([0-9A-Za-z.-]+)-)?eksbuild\.([0-9]+)$`)
+
+// Version is a semantic version, which is aware of the EKS build suffix
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	// Variant is the variant of an EKS build, for example minimal for v1.27.1-minimal-eksbuild.1
+	Variant     string
+	EksBuild    int
+	HasEksBuild bool
+	// parts is the number of the major, minor and patch versions which were set, partial versions are used in
+	// constraints
+	parts int
+}
+
+// Parse returns the version of the passed version string
+func Parse(version string) (Version, error) {
+	matches := versionRegex.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return Version{}, fmt.Errorf("invalid version %s", version)
+	}
+
+	v := Version{parts: 1}
+	v.Major, _ = strconv.Atoi(matches[1])
+	if matches[2] != "" {
+		v.Minor, _ = strconv.Atoi(matches[2])
+		v.parts = 2
+	}
+	if matches[3] != "" {
+		v.Patch, _ = strconv.Atoi(matches[3])
+		v.parts = 3
+	}
+	if eksBuildMatches := eksBuildRegex.FindStringSubmatch(matches[4]); eksBuildMatches != nil {
+		v.Variant = eksBuildMatches[1]
+		v.EksBuild, _ = strconv.Atoi(eksBuildMatches[2])
+		v.HasEksBuild = true
+	} else {
+		v.Prerelease = matches[4]
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 when the version is lower than, equal to or higher than the other version. Pre-releases
+// are lower than the release, EKS builds are higher than the release and ordered by their build number. The variant of
+// EKS builds is not taken into account
+func (v Version) Compare(other Version) int {
+	if result := v.compareRelease(other); result != 0 {
+		return result
+	}
+	return compareInts(v.EksBuild, other.EksBuild)
+}
+
+// compareRelease compares the versions without taking the EKS build into account
+func (v Version) compareRelease(other Version) int {
+	if result := compareInts(v.Major, other.Major); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Minor, other.Minor); result != 0 {
+		return result
+	}
+	if result := compareInts(v.Patch, other.Patch); result != 0 {
+		return result
+	}
+	return comparePrereleases(v.Prerelease, other.Prerelease)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePrereleases compares the pre-releases by their dot separated identifiers, numeric identifiers are compared
+// numerically, a version without pre-release is higher than one with
+func comparePrereleases(a, b string) int {
+	if a == b {
+		return 0
+	}
+	if a == "" {
+		return 1
+	}
+	if b == "" {
+		return -1
+	}
+
+	aIdentifiers, bIdentifiers := strings.Split(a, "."), strings.Split(b, ".")
+	for index := 0; index < len(aIdentifiers) && index < len(bIdentifiers); index++ {
+		aNumber, aErr := strconv.Atoi(aIdentifiers[index])
+		bNumber, bErr := strconv.Atoi(bIdentifiers[index])
+		var result int
+		switch {
+		case aErr == nil && bErr == nil:
+			result = compareInts(aNumber, bNumber)
+		case aErr == nil:
+			result = -1
+		case bErr == nil:
+			result = 1
+		default:
+			result = strings.Compare(aIdentifiers[index], bIdentifiers[index])
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return compareInts(len(aIdentifiers), len(bIdentifiers))
+}
+
+// Result is the outcome of checking a version against a desired version or constraint
+type Result int
+
+const (
+	Satisfies Result = iota
+	OlderThanRequired
+	NewerThanRequired
+	// Differs is the result when the versions differ but can't be ordered, for example when one of them is not a
+	// semantic version
+	Differs
+)
+
+func (r Result) String() string {
+	switch r {
+	case Satisfies:
+		return "satisfies"
+	case OlderThanRequired:
+		return "older than required"
+	case NewerThanRequired:
+		return "newer than required"
+	}
+	return "differs"
+}
+
+type comparator struct {
+	operator string
+	version  Version
+}
+
+// Constraint is a list of space separated comparators a version needs to satisfy all of, for example ">=1.10.1 <1.11".
+// The operators are =, >, >=, <, <=, ~ and ^
+//   - ~1.10 and ~1.10.1 allow patch versions, they equal >=1.10.0 <1.11.0 and >=1.10.1 <1.11.0
+//   - ^1.10.1 allows minor and patch versions, it equals >=1.10.1 <2.0.0
+//
+// Partial versions are completed with zeros. The EKS build of a version is only taken into account when the version of
+// the comparator has one, so v1.10.1-eksbuild.3 satisfies both =1.10.1 and <=1.10.1, and doesn't satisfy >1.10.1
+type Constraint struct {
+	comparators []comparator
+	original    string
+}
+
+// IsConstraint returns whether the passed value is a constraint rather than a version
+func IsConstraint(value string) bool {
+	value = strings.TrimSpace(value)
+	return strings.ContainsAny(value, " <>=~^")
+}
+
+// ParseConstraint returns the constraint of the passed constraint string
+func ParseConstraint(constraint string) (Constraint, error) {
+	fields := strings.Fields(constraint)
+	if len(fields) == 0 {
+		return Constraint{}, errors.New("empty constraint")
+	}
+
+	c := Constraint{original: strings.Join(fields, " ")}
+	for _, field := range fields {
+		operator := field[:len(field)-len(strings.TrimLeft(field, "<>=~^"))]
+		version, err := Parse(strings.TrimPrefix(field, operator))
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid constraint %s, %s", constraint, err)
+		}
+
+		switch operator {
+		case "", "=", ">", ">=", "<", "<=":
+			if operator == "" {
+				operator = "="
+			}
+			c.comparators = append(c.comparators, comparator{operator, version})
+		case "~":
+			upper := Version{Major: version.Major, Minor: version.Minor + 1, parts: 3}
+			if version.parts == 1 {
+				upper = Version{Major: version.Major + 1, parts: 3}
+			}
+			c.comparators = append(c.comparators, comparator{">=", version}, comparator{"<", upper})
+		case "^":
+			c.comparators = append(c.comparators, comparator{">=", version}, comparator{"<", Version{Major: version.Major + 1, parts: 3}})
+		default:
+			return Constraint{}, fmt.Errorf("invalid constraint %s, unknown operator %s", constraint, operator)
+		}
+	}
+	return c, nil
+}
+
+func (c Constraint) String() string {
+	return c.original
+}
+
+// Check returns whether the version satisfies the constraint, or whether it is older or newer than the constraint
+// requires
+func (c Constraint) Check(version Version) Result {
+	for _, comparator := range c.comparators {
+		result := comparator.compare(version)
+		switch comparator.operator {
+		case "=":
+			if result < 0 {
+				return OlderThanRequired
+			} else if result > 0 {
+				return NewerThanRequired
+			}
+		case ">":
+			if result <= 0 {
+				return OlderThanRequired
+			}
+		case ">=":
+			if result < 0 {
+				return OlderThanRequired
+			}
+		case "<":
+			if result >= 0 {
+				return NewerThanRequired
+			}
+		case "<=":
+			if result > 0 {
+				return NewerThanRequired
+			}
+		}
+	}
+	return Satisfies
+}
+
+// compare compares the version with the version of the comparator, taking the EKS build into account only when the
+// version of the comparator has one
+func (c comparator) compare(version Version) int {
+	if c.version.HasEksBuild {
+		return version.Compare(c.version)
+	}
+	return version.compareRelease(c.version)
+}
+
+// Check returns whether the running version satisfies the desired value, which is either a constraint or a version
+// the running version needs to be equal to
+func Check(running, desired string) (Result, error) {
+	if IsConstraint(desired) {
+		constraint, err := ParseConstraint(desired)
+		if err != nil {
+			return Differs, err
+		}
+		runningVersion, err := Parse(running)
+		if err != nil {
+			return Differs, nil
+		}
+		return constraint.Check(runningVersion), nil
+	}
+
+	if running == desired {
+		return Satisfies, nil
+	}
+	runningVersion, runningErr := Parse(running)
+	desiredVersion, desiredErr := Parse(desired)
+	if runningErr != nil || desiredErr != nil {
+		return Differs, nil
+	}
+	switch runningVersion.Compare(desiredVersion) {
+	case -1:
+		return OlderThanRequired, nil
+	case 1:
+		return NewerThanRequired, nil
+	}
+	// the versions are equal while written differently, like 1.10.1 and v1.10.1, unless they are of another variant
+	if runningVersion.Variant != desiredVersion.Variant {
+		return Differs, nil
+	}
+	return Satisfies, nil
+}
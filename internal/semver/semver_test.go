@@ -0,0 +1,142 @@
+package semver
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    Version
+		err     error
+	}{
+		{"when the version has a v prefix", "v1.20.14", Version{Major: 1, Minor: 20, Patch: 14, parts: 3}, nil},
+		{"when the version has no v prefix", "1.8.4", Version{Major: 1, Minor: 8, Patch: 4, parts: 3}, nil},
+		{"when the version has an EKS build", "v1.10.1-eksbuild.2", Version{Major: 1, Minor: 10, Patch: 1, EksBuild: 2, HasEksBuild: true, parts: 3}, nil},
+		{"when the version has an EKS build of a variant", "v1.27.1-minimal-eksbuild.1",
+			Version{Major: 1, Minor: 27, Patch: 1, Variant: "minimal", EksBuild: 1, HasEksBuild: true, parts: 3}, nil},
+		{"when the version has a pre-release", "v1.2.0-rc.1", Version{Major: 1, Minor: 2, Prerelease: "rc.1", parts: 3}, nil},
+		{"when the version is partial", "1.10", Version{Major: 1, Minor: 10, parts: 2}, nil},
+		{"when the version is not a version it returns an error", "latest", Version{}, errors.New("invalid version latest")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.version)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		result int
+	}{
+		{"when the patch version is lower", "v1.10.0", "v1.10.1", -1},
+		{"when the versions only differ by their v prefix", "1.10.1", "v1.10.1", 0},
+		{"when the minor version is higher, compared numerically", "v1.10.0", "v1.9.3", 1},
+		{"when the EKS build is lower", "v1.10.1-eksbuild.2", "v1.10.1-eksbuild.3", -1},
+		{"when one of the versions is an EKS build of the other", "v1.10.1-eksbuild.1", "v1.10.1", 1},
+		{"when one of the versions is a pre-release of the other", "v1.10.1-rc.1", "v1.10.1", -1},
+		{"when the pre-releases differ by a numeric identifier", "v1.10.1-rc.10", "v1.10.1-rc.9", 1},
+		{"when the EKS builds are of different variants", "v1.27.1-minimal-eksbuild.1", "v1.27.1-eksbuild.1", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := Parse(tt.a)
+			assert.Nil(t, err)
+			b, err := Parse(tt.b)
+			assert.Nil(t, err)
+
+			assert.Equal(t, tt.result, a.Compare(b))
+		})
+	}
+}
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		err        error
+	}{
+		{"when the constraint is a range", ">=1.10.1 <1.11", nil},
+		{"when the constraint is a tilde range", "~1.10", nil},
+		{"when the constraint is a caret range", "^v1.10.1-eksbuild.2", nil},
+		{"when the constraint is empty it returns an error", " ", errors.New("empty constraint")},
+		{"when the operator is unknown it returns an error", "=>1.10", errors.New("invalid constraint =>1.10, unknown operator =>")},
+		{"when the version is invalid it returns an error", ">=latest", errors.New("invalid constraint >=latest, invalid version latest")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConstraint(tt.constraint)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestConstraint_Check(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		version    string
+		result     Result
+	}{
+		{"when the version is within the range", ">=1.10.1 <1.11", "v1.10.1-eksbuild.3", Satisfies},
+		{"when the version is below the range", ">=1.10.1 <1.11", "v1.10.0-eksbuild.1", OlderThanRequired},
+		{"when the version is above the range", ">=1.10.1 <1.11", "v1.11.0", NewerThanRequired},
+		{"when the version is an EKS build of the upper bound", ">=1.10.1 <1.11", "v1.11.0-eksbuild.1", NewerThanRequired},
+		{"when the version is a patch version of the tilde range", "~1.10", "v1.10.4-eksbuild.1", Satisfies},
+		{"when the version is the next minor version of the tilde range", "~1.10", "v1.11.0", NewerThanRequired},
+		{"when the version is below the patch version of the tilde range", "~1.10.2", "v1.10.1", OlderThanRequired},
+		{"when the version is a minor version of the caret range", "^1.10.1", "v1.12.0", Satisfies},
+		{"when the version is the next major version of the caret range", "^1.10.1", "v2.0.0", NewerThanRequired},
+		{"when the EKS build is below the EKS build of the constraint", ">=v1.10.1-eksbuild.2", "v1.10.1-eksbuild.1", OlderThanRequired},
+		{"when the EKS build is above the EKS build of the constraint", ">=v1.10.1-eksbuild.2", "v1.10.1-eksbuild.3", Satisfies},
+		{"when the version equals the constraint version without an EKS build", "=1.10.1", "v1.10.1-eksbuild.3", Satisfies},
+		{"when the version is an EKS build of the exclusive lower bound", ">1.10.1", "v1.10.1-eksbuild.3", OlderThanRequired},
+		{"when the version is an EKS build of the inclusive upper bound", "<=1.10.1", "v1.10.1-eksbuild.3", Satisfies},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraint, err := ParseConstraint(tt.constraint)
+			assert.Nil(t, err)
+			version, err := Parse(tt.version)
+			assert.Nil(t, err)
+
+			assert.Equal(t, tt.result, constraint.Check(version))
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		running string
+		desired string
+		result  Result
+		err     error
+	}{
+		{"when the running version equals the desired version", "v1.10.1-eksbuild.2", "v1.10.1-eksbuild.2", Satisfies, nil},
+		{"when the running version is older than the desired version", "v1.10.1-eksbuild.2", "v1.10.1-eksbuild.3", OlderThanRequired, nil},
+		{"when the running version is newer than the desired version", "v1.10.1-eksbuild.3", "v1.10.1-eksbuild.2", NewerThanRequired, nil},
+		{"when the running version satisfies the desired constraint", "v1.10.1-eksbuild.3", ">=1.10.1 <1.11", Satisfies, nil},
+		{"when the running version equals the desired version written differently", "1.10.1", "v1.10.1", Satisfies, nil},
+		{"when the running version is of another variant than the desired version", "v1.27.1-minimal-eksbuild.1", "v1.27.1-eksbuild.1", Differs, nil},
+		{"when the running version is not a version", "latest", "v1.8.4", Differs, nil},
+		{"when the desired constraint is invalid it returns an error", "v1.8.4", ">=latest", Differs,
+			errors.New("invalid constraint >=latest, invalid version latest")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Check(tt.running, tt.desired)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}